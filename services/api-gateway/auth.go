@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"ride-sharing/shared/auth"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/env"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	tokens = auth.NewTokenManager(
+		[]byte(env.GetString("AUTH_TOKEN_SECRET", "")),
+		time.Duration(env.GetInt("AUTH_TOKEN_TTL_MINUTES", 24*60))*time.Minute,
+	)
+)
+
+// UserStore checks the credentials of a login and returns who they belong to.
+type UserStore interface {
+	Authenticate(ctx context.Context, username, password string, role auth.Role) (*auth.Identity, error)
+}
+
+// guestUserStore lets anyone in under a fresh identity, which is what the demo
+// frontend needs: every browser tab is a new rider or driver.
+type guestUserStore struct{}
+
+func (guestUserStore) Authenticate(ctx context.Context, username, password string, role auth.Role) (*auth.Identity, error) {
+	return &auth.Identity{UserID: uuid.NewString(), Role: role}, nil
+}
+
+type staticUser struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"passwordHash"` // bcrypt
+	UserID       string    `json:"userID"`
+	Role         auth.Role `json:"role"`
+}
+
+// staticUserStore authenticates against a fixed list of users loaded from a JSON file.
+type staticUserStore struct {
+	users map[string]staticUser
+}
+
+func loadStaticUserStore(path string) (*staticUserStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users file: %w", err)
+	}
+
+	var users []staticUser
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse users file: %w", err)
+	}
+
+	store := &staticUserStore{users: make(map[string]staticUser, len(users))}
+	for _, u := range users {
+		if u.Username == "" || u.UserID == "" || !u.Role.Valid() {
+			return nil, fmt.Errorf("user %q needs a username, a userID and a rider or driver role", u.Username)
+		}
+		store.users[u.Username] = u
+	}
+
+	return store, nil
+}
+
+func (s *staticUserStore) Authenticate(ctx context.Context, username, password string, role auth.Role) (*auth.Identity, error) {
+	u, ok := s.users[username]
+	if !ok || u.Role != role {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &auth.Identity{UserID: u.UserID, Role: u.Role}, nil
+}
+
+// newUserStore picks the user store from AUTH_USER_STORE: guest (the default) or static,
+// which reads its users from AUTH_USERS_FILE.
+func newUserStore() (UserStore, error) {
+	switch kind := env.GetString("AUTH_USER_STORE", "guest"); kind {
+	case "guest":
+		return guestUserStore{}, nil
+	case "static":
+		return loadStaticUserStore(env.GetString("AUTH_USERS_FILE", ""))
+	default:
+		return nil, fmt.Errorf("unknown user store %q", kind)
+	}
+}
+
+type loginRequest struct {
+	Username string    `json:"username"`
+	Password string    `json:"password"`
+	Role     auth.Role `json:"role"`
+}
+
+type loginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	UserID    string    `json:"userID"`
+	Role      auth.Role `json:"role"`
+}
+
+func handleLogin(w http.ResponseWriter, r *http.Request, users UserStore) {
+	defer r.Body.Close()
+
+	var reqBody loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !reqBody.Role.Valid() {
+		http.Error(w, "Role must be rider or driver", http.StatusBadRequest)
+		return
+	}
+
+	identity, err := users.Authenticate(r.Context(), reqBody.Username, reqBody.Password, reqBody.Role)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			return
+		}
+		log.Printf("Failed to authenticate user: %v", err)
+		http.Error(w, "Failed to authenticate user", http.StatusInternalServerError)
+		return
+	}
+
+	token, expiresAt, err := tokens.Issue(*identity, time.Now())
+	if err != nil {
+		log.Printf("Failed to issue token: %v", err)
+		http.Error(w, "Failed to issue token", http.StatusInternalServerError)
+		return
+	}
+
+	response := contracts.APIResponse{
+		Data: loginResponse{
+			Token:     token,
+			ExpiresAt: expiresAt,
+			UserID:    identity.UserID,
+			Role:      identity.Role,
+		},
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// requireAuth rejects requests without a valid bearer token of the given role and
+// passes the verified identity to the handler through the request context.
+func requireAuth(role auth.Role, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		identity, err := tokens.Verify(token, time.Now())
+		if err != nil {
+			http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		if role != "" && identity.Role != role {
+			http.Error(w, "Forbidden for "+string(identity.Role)+"s", http.StatusForbidden)
+			return
+		}
+
+		handler(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+	}
+}
+
+// identityFrom returns the identity requireAuth stored in the request context.
+func identityFrom(r *http.Request) *auth.Identity {
+	identity, _ := auth.FromContext(r.Context())
+	return identity
+}
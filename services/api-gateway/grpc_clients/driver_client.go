@@ -2,6 +2,7 @@ package grpc_clients
 
 import (
 	"os"
+	"ride-sharing/shared/auth"
 	driverGrpc "ride-sharing/shared/proto/driver"
 	"ride-sharing/shared/tracing"
 
@@ -21,6 +22,7 @@ func NewDriverServiceClient() (*driverServiceClient, error) {
 	}
 
 	dialOptions := append(tracing.DialOptionsWithTracing(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	dialOptions = append(dialOptions, auth.DialOptions()...)
 	conn, err := grpc.NewClient(driverServiceUrl, dialOptions...)
 
 	if err != nil {
@@ -0,0 +1,42 @@
+package grpc_clients
+
+import (
+	"os"
+	"ride-sharing/shared/auth"
+	paymentGrpc "ride-sharing/shared/proto/payment"
+	"ride-sharing/shared/tracing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type paymentServiceClient struct {
+	Client paymentGrpc.PaymentServiceClient
+	conn   *grpc.ClientConn
+}
+
+func NewPaymentServiceClient() (*paymentServiceClient, error) {
+	paymentServiceUrl := os.Getenv("PAYMENT_SERVICE_URL")
+	if paymentServiceUrl == "" {
+		paymentServiceUrl = "payment-service:9004"
+	}
+
+	dialOptions := append(tracing.DialOptionsWithTracing(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	dialOptions = append(dialOptions, auth.DialOptions()...)
+
+	conn, err := grpc.NewClient(paymentServiceUrl, dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &paymentServiceClient{
+		Client: paymentGrpc.NewPaymentServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+func (c *paymentServiceClient) Close() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
@@ -2,6 +2,7 @@ package grpc_clients
 
 import (
 	"os"
+	"ride-sharing/shared/auth"
 	tripGrpc "ride-sharing/shared/proto/trip"
 	"ride-sharing/shared/tracing"
 
@@ -21,6 +22,7 @@ func NewTripServiceClient() (*tripServiceClient, error) {
 	}
 
 	dialOptions := append(tracing.DialOptionsWithTracing(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	dialOptions = append(dialOptions, auth.DialOptions()...)
 
 	conn, err := grpc.NewClient(tripServiceUrl, dialOptions...)
 
@@ -7,12 +7,13 @@ import (
 	"net/http"
 	"ride-sharing/services/api-gateway/grpc_clients"
 	"ride-sharing/shared/contracts"
-	"ride-sharing/shared/env"
-	"ride-sharing/shared/messaging"
+	paymentGrpc "ride-sharing/shared/proto/payment"
+	tripGrpc "ride-sharing/shared/proto/trip"
 	"ride-sharing/shared/tracing"
+	"strconv"
 
-	"github.com/stripe/stripe-go/v81"
-	"github.com/stripe/stripe-go/v81/webhook"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var tracer = tracing.GetTracer("api-gateway")
@@ -30,11 +31,6 @@ func handleTripPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if reqBody.UserID == "" {
-		http.Error(w, "User ID is required", http.StatusBadRequest)
-		return
-	}
-
 	tripService, err := grpc_clients.NewTripServiceClient()
 
 	if err != nil {
@@ -44,7 +40,7 @@ func handleTripPreview(w http.ResponseWriter, r *http.Request) {
 
 	defer tripService.Close()
 
-	tripPreview, err := tripService.Client.PreviewTrip(ctx, reqBody.toProto())
+	tripPreview, err := tripService.Client.PreviewTrip(ctx, reqBody.toProto(identityFrom(r).UserID))
 
 	if err != nil {
 		log.Printf("Failed to preview trip: %v", err)
@@ -73,11 +69,6 @@ func handleTripStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if reqBody.UserID == "" {
-		http.Error(w, "User ID is required", http.StatusBadRequest)
-		return
-	}
-
 	tripService, err := grpc_clients.NewTripServiceClient()
 
 	if err != nil {
@@ -87,11 +78,11 @@ func handleTripStart(w http.ResponseWriter, r *http.Request) {
 
 	defer tripService.Close()
 
-	createdTrip, err := tripService.Client.CreateTrip(ctx, reqBody.toProto())
+	createdTrip, err := tripService.Client.CreateTrip(ctx, reqBody.toProto(identityFrom(r).UserID))
 
 	if err != nil {
 		log.Printf("Failed to create trip: %v", err)
-		http.Error(w, "Failed to create trip: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to create trip: "+err.Error(), httpStatusFromGRPC(err))
 		return
 	}
 
@@ -103,74 +94,181 @@ func handleTripStart(w http.ResponseWriter, r *http.Request) {
 
 }
 
-func handleStripeWebhook(w http.ResponseWriter, r *http.Request, rb *messaging.RabbitMQ) {
-	ctx, span := tracer.Start(r.Context(), "handleStripeWebhook")
+func handleTripCancel(w http.ResponseWriter, r *http.Request) {
+
+	ctx, span := tracer.Start(r.Context(), "handleTripCancel")
 	defer span.End()
-	body, err := io.ReadAll(r.Body)
+
+	defer r.Body.Close()
+
+	var reqBody cancelTripRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if reqBody.TripID == "" {
+		http.Error(w, "Trip ID is required", http.StatusBadRequest)
+		return
+	}
+
+	tripService, err := grpc_clients.NewTripServiceClient()
 
 	if err != nil {
-		log.Printf("Error reading request body: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		http.Error(w, "Failed to create trip service client", http.StatusInternalServerError)
 		return
 	}
 
-	defer r.Body.Close()
+	defer tripService.Close()
 
-	webhookKey := env.GetString("STRIPE_WEBHOOK_KEY", "")
+	cancelledTrip, err := tripService.Client.CancelTrip(ctx, reqBody.toProto(identityFrom(r).UserID))
 
-	if webhookKey == "" {
-		log.Println("Stripe webhook key is not set")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	if err != nil {
+		log.Printf("Failed to cancel trip: %v", err)
+		http.Error(w, "Failed to cancel trip: "+err.Error(), httpStatusFromGRPC(err))
 		return
 	}
 
-	event, err := webhook.ConstructEventWithOptions(body, r.Header.Get("Stripe-Signature"), webhookKey, webhook.ConstructEventOptions{
-		IgnoreAPIVersionMismatch: true,
+	response := contracts.APIResponse{
+		Data: cancelledTrip,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+
+}
+
+func handleGetTrip(w http.ResponseWriter, r *http.Request) {
+
+	ctx, span := tracer.Start(r.Context(), "handleGetTrip")
+	defer span.End()
+
+	tripService, err := grpc_clients.NewTripServiceClient()
+
+	if err != nil {
+		http.Error(w, "Failed to create trip service client", http.StatusInternalServerError)
+		return
+	}
+
+	defer tripService.Close()
+
+	trip, err := tripService.Client.GetTrip(ctx, &tripGrpc.GetTripRequest{
+		TripID: r.PathValue("id"),
 	})
 
 	if err != nil {
-		log.Printf("Error verifying webhook signature: %v", err)
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+		log.Printf("Failed to get trip: %v", err)
+		http.Error(w, "Failed to get trip: "+err.Error(), httpStatusFromGRPC(err))
 		return
 	}
 
-	switch event.Type {
-	case "checkout.session.completed":
-		var session stripe.CheckoutSession
+	response := contracts.APIResponse{
+		Data: trip,
+	}
 
-		err := json.Unmarshal(event.Data.Raw, &session)
-		if err != nil {
-			log.Printf("Error parsing webhook JSON: %v", err)
-			http.Error(w, "Invalid payload", http.StatusBadRequest)
-			return
-		}
+	writeJSON(w, http.StatusOK, response)
 
-		payload := messaging.PaymentStatusUpdateData{
-			TripID:   session.Metadata["trip_id"],
-			UserID:   session.Metadata["user_id"],
-			DriverID: session.Metadata["driver_id"],
-		}
+}
 
-		payloadBytes, err := json.Marshal(payload)
-		if err != nil {
-			log.Printf("Error marshalling payload: %v", err)
-			http.Error(w, "Failed to marshal payload", http.StatusInternalServerError)
-			return
-		}
+func handleListUserTrips(w http.ResponseWriter, r *http.Request) {
 
-		message := contracts.AmqpMessage{
-			OwnerID: session.Metadata["user_id"],
-			Data:    payloadBytes,
-		}
+	ctx, span := tracer.Start(r.Context(), "handleListUserTrips")
+	defer span.End()
 
-		if err := rb.PublishMessage(
-			ctx,
-			contracts.PaymentEventSuccess,
-			&message,
-		); err != nil {
-			log.Printf("Error publishing payment event: %v", err)
-			http.Error(w, "Failed to publish payment event", http.StatusInternalServerError)
+	if r.PathValue("id") != identityFrom(r).UserID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var pageSize int
+	if raw := query.Get("pageSize"); raw != "" {
+		var err error
+		if pageSize, err = strconv.Atoi(raw); err != nil || pageSize < 0 {
+			http.Error(w, "pageSize must be a positive number", http.StatusBadRequest)
 			return
 		}
 	}
+
+	tripService, err := grpc_clients.NewTripServiceClient()
+
+	if err != nil {
+		http.Error(w, "Failed to create trip service client", http.StatusInternalServerError)
+		return
+	}
+
+	defer tripService.Close()
+
+	trips, err := tripService.Client.ListTripsByUser(ctx, &tripGrpc.ListTripsByUserRequest{
+		UserID:    r.PathValue("id"),
+		PageSize:  int32(pageSize),
+		PageToken: query.Get("pageToken"),
+	})
+
+	if err != nil {
+		log.Printf("Failed to list trips: %v", err)
+		http.Error(w, "Failed to list trips: "+err.Error(), httpStatusFromGRPC(err))
+		return
+	}
+
+	response := contracts.APIResponse{
+		Data: trips,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+
+}
+
+// httpStatusFromGRPC maps the gRPC status of a downstream error to the closest HTTP status
+func httpStatusFromGRPC(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.FailedPrecondition, codes.AlreadyExists:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// handleStripeWebhook forwards the delivery to payment-service, which verifies its signature
+// and publishes the payment event. Stripe retries the deliveries that do not get a 2xx.
+func handleStripeWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "handleStripeWebhook")
+	defer span.End()
+	body, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		log.Printf("Error reading request body: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	defer r.Body.Close()
+
+	paymentService, err := grpc_clients.NewPaymentServiceClient()
+	if err != nil {
+		http.Error(w, "Failed to create payment service client", http.StatusInternalServerError)
+		return
+	}
+
+	defer paymentService.Close()
+
+	result, err := paymentService.Client.HandleStripeWebhook(ctx, &paymentGrpc.HandleStripeWebhookRequest{
+		Payload:   body,
+		Signature: r.Header.Get("Stripe-Signature"),
+	})
+	if err != nil {
+		log.Printf("Failed to handle Stripe webhook: %v", err)
+		http.Error(w, "Failed to handle webhook", httpStatusFromGRPC(err))
+		return
+	}
+
+	if result.GetDuplicate() {
+		log.Printf("Stripe event %s was already processed", result.GetEventID())
+	}
 }
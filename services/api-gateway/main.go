@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"ride-sharing/shared/auth"
 	"ride-sharing/shared/env"
 	"ride-sharing/shared/messaging"
 	"ride-sharing/shared/tracing"
@@ -40,31 +41,60 @@ func main() {
 	defer cancel()
 	defer tracingShutdown(ctx)
 
+	if env.GetString("AUTH_TOKEN_SECRET", "") == "" {
+		log.Fatal("AUTH_TOKEN_SECRET must be set to sign access tokens")
+	}
+
+	users, err := newUserStore()
+	if err != nil {
+		log.Fatalf("Failed to create user store: %v", err)
+	}
+
 	// init RabbitMQ
-	rabbitmq, err := messaging.NewRabbitMQ(rabbitmqUri)
+	rabbitmq, err := messaging.NewRabbitMQ(rabbitmqUri, messaging.NewRetryDefaultConfig())
 	if err != nil {
 		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
 	}
 
 	defer rabbitmq.Close()
+
+	// a single consumer per instance forwards messages to the WebSockets connected to it
+	notifications := messaging.NewQueueConsumer(rabbitmq, connManager, messaging.GatewayQueuePrefix, notifiedRoutingKeys)
+	if err := notifications.Start(); err != nil {
+		log.Fatalf("Failed to start the notifications consumer: %v", err)
+	}
+	defer notifications.Stop()
+
 	mux := http.NewServeMux()
 
-	mux.Handle("POST /trip/preview", tracing.WrapHandlerFunc(enableCORS(handleTripPreview), "/trip/preview"))
-	mux.Handle("POST /trip/start", tracing.WrapHandlerFunc(enableCORS(handleTripStart), "/trip/start"))
+	mux.Handle("POST /auth/login", tracing.WrapHandlerFunc(enableCORS(func(w http.ResponseWriter, r *http.Request) {
+		handleLogin(w, r, users)
+	}), "/auth/login"))
+	mux.Handle("POST /trip/preview", tracing.WrapHandlerFunc(enableCORS(requireAuth(auth.RoleRider, handleTripPreview)), "/trip/preview"))
+	mux.Handle("POST /trip/start", tracing.WrapHandlerFunc(enableCORS(requireAuth(auth.RoleRider, handleTripStart)), "/trip/start"))
+	// riders and the driver of a trip can both cancel it
+	mux.Handle("POST /trip/cancel", tracing.WrapHandlerFunc(enableCORS(requireAuth("", handleTripCancel)), "/trip/cancel"))
+	mux.Handle("GET /trip/{id}", tracing.WrapHandlerFunc(enableCORS(requireAuth("", handleGetTrip)), "/trip/{id}"))
+	mux.Handle("GET /users/{id}/trips", tracing.WrapHandlerFunc(enableCORS(requireAuth("", handleListUserTrips)), "/users/{id}/trips"))
+
+	// readiness probe, fails while the gateway is reconnecting to RabbitMQ
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !rabbitmq.Ready() {
+			http.Error(w, "RabbitMQ is not connected", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
 
 	mux.Handle("ws/drivers", tracing.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		handleDriversWebSocket(w, r, rabbitmq)
 	}, "/ws/drivers"))
 
-	mux.Handle("ws/riders", tracing.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		handleRidersWebSocket(w, r, rabbitmq)
-	}, "/ws/riders"))
+	mux.Handle("ws/riders", tracing.WrapHandlerFunc(handleRidersWebSocket, "/ws/riders"))
 	// install stripe cli
 	// stripe listen --forward-to localhost:8081/webhook/stripe
-	// update the webhook secret in the k8s secret as stripe-webhook-key
-	mux.Handle("/webhook/stripe", tracing.WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		handleStripeWebhook(w, r, rabbitmq)
-	}, "/webhook/stripe"))
+	// update the webhook secret in the k8s secret as stripe-webhook-key, payment-service verifies it
+	mux.Handle("/webhook/stripe", tracing.WrapHandlerFunc(handleStripeWebhook, "/webhook/stripe"))
 
 	server := &http.Server{
 		Addr:    httpAddr,
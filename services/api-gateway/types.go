@@ -1,19 +1,19 @@
 package main
 
 import (
+	driverGrpc "ride-sharing/shared/proto/driver"
 	tripGrpc "ride-sharing/shared/proto/trip"
 	"ride-sharing/shared/types"
 )
 
 type previewTripRequest struct {
-	UserID      string           `json:"userId"`
 	Pickup      types.Coordinate `json:"pickup"`
 	Destination types.Coordinate `json:"destination"`
 }
 
-func (p *previewTripRequest) toProto() *tripGrpc.PreviewTripRequest {
+func (p *previewTripRequest) toProto(userID string) *tripGrpc.PreviewTripRequest {
 	return &tripGrpc.PreviewTripRequest{
-		UserID: p.UserID,
+		UserID: userID,
 		StartLocation: &tripGrpc.Coordinate{
 			Latitude:  p.Pickup.Latitude,
 			Longitude: p.Pickup.Longitude,
@@ -27,12 +27,36 @@ func (p *previewTripRequest) toProto() *tripGrpc.PreviewTripRequest {
 
 type startTripRequest struct {
 	RideFareID string `json:"rideFareID"`
-	UserID     string `json:"userID"`
 }
 
-func (s *startTripRequest) toProto() *tripGrpc.CreateTripRequest {
+func (s *startTripRequest) toProto(userID string) *tripGrpc.CreateTripRequest {
 	return &tripGrpc.CreateTripRequest{
 		RideFareID: s.RideFareID,
-		UserID:     s.UserID,
+		UserID:     userID,
+	}
+}
+
+type cancelTripRequest struct {
+	TripID string `json:"tripID"`
+}
+
+func (c *cancelTripRequest) toProto(userID string) *tripGrpc.CancelTripRequest {
+	return &tripGrpc.CancelTripRequest{
+		TripID: c.TripID,
+		UserID: userID,
+	}
+}
+
+type driverLocationMessage struct {
+	Location types.Coordinate `json:"location"`
+}
+
+func (d *driverLocationMessage) toProto(driverID string) *driverGrpc.UpdateLocationRequest {
+	return &driverGrpc.UpdateLocationRequest{
+		DriverID: driverID,
+		Location: &driverGrpc.Location{
+			Latitude:  d.Location.Latitude,
+			Longitude: d.Location.Longitude,
+		},
 	}
 }
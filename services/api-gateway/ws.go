@@ -1,54 +1,54 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	"ride-sharing/services/api-gateway/grpc_clients"
+	"ride-sharing/shared/auth"
 	"ride-sharing/shared/contracts"
 	"ride-sharing/shared/messaging"
 
 	driverGrpc "ride-sharing/shared/proto/driver"
+
+	"google.golang.org/protobuf/proto"
 )
 
 var (
-	connManager = messaging.NewConnectionManager()
+	connManager = messaging.NewConnectionManager(tokens)
+
+	// routing keys forwarded to the WebSocket of the message's owner
+	notifiedRoutingKeys = []string{
+		contracts.TripEventNoDriversFound,
+		contracts.TripEventDriverAssigned,
+		contracts.PaymentEventSessionCreated,
+		contracts.PaymentEventFailed,
+		contracts.PaymentEventCancelled,
+		contracts.PaymentEventRefunded,
+		contracts.TripEventCancelled,
+		contracts.DriverEventLocationUpdated,
+		contracts.DriverCmdTripRequest,
+		contracts.DriverEventTripCancelled,
+	}
 )
 
-func handleRidersWebSocket(w http.ResponseWriter, r *http.Request, rb *messaging.RabbitMQ) {
-	conn, err := connManager.Upgrade(w, r)
+func handleRidersWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, identity, err := connManager.Upgrade(w, r, auth.RoleRider)
 
 	if err != nil {
 		log.Printf("Websocket upgrade failed: %v", err)
+		return
 	}
 	defer conn.Close()
 
-	userID := r.URL.Query().Get("userID")
-
-	if userID == "" {
-		log.Println("User ID is required for WebSocket connection")
-		http.Error(w, "User ID is required", http.StatusBadRequest)
-		return
-	}
+	userID := identity.UserID
 
 	connManager.Add(userID, conn)
-	defer connManager.Remove(userID)
-
-	// initialize queue consumers
-	queues := []string{
-		messaging.NotifyDriverNoDriversFoundQueue,
-		messaging.NotifyDriverAssignQueue,
-		messaging.NotifyPaymentSessionCreatedQueue,
-	}
-
-	for _, q := range queues {
-		consumer := messaging.NewQueueConsumer(rb, connManager, q)
-		if err := consumer.Start(); err != nil {
-			log.Printf("Error starting consumer for queue %s: %v", q, err)
-		}
-	}
+	defer connManager.Remove(userID, conn)
 
 	// reading messages from the rider from its ws connection. there is nothing they do now. they actually send http requests to the API gateway.
+	// the read also fails once the rider stops answering pings, which ends the handler.
 	for {
 		_, message, err := conn.ReadMessage()
 
@@ -57,38 +57,40 @@ func handleRidersWebSocket(w http.ResponseWriter, r *http.Request, rb *messaging
 			break
 		}
 
+		var riderMsg contracts.WSDriverMessage
+		if err := json.Unmarshal(message, &riderMsg); err != nil {
+			sendError(userID, "", contracts.WSErrorBadRequest, "invalid message")
+			continue
+		}
+
 		log.Printf("Received message from rider %s: %s", userID, message)
+		sendError(userID, riderMsg.ID, contracts.WSErrorUnknownType, "riders cannot send "+riderMsg.Type)
 	}
 }
 
 func handleDriversWebSocket(w http.ResponseWriter, r *http.Request, rb *messaging.RabbitMQ) {
-	conn, err := connManager.Upgrade(w, r)
+	packageSlug := r.URL.Query().Get("packageSlug")
 
-	if err != nil {
-		log.Printf("Websocket upgrade failed: %v", err)
+	if packageSlug == "" {
+		log.Println("Package slug is required for WebSocket connection")
+		http.Error(w, "Package slug is required", http.StatusBadRequest)
+		return
 	}
 
-	defer conn.Close()
-
-	userID := r.URL.Query().Get("userID")
+	conn, identity, err := connManager.Upgrade(w, r, auth.RoleDriver)
 
-	if userID == "" {
-		log.Println("User ID is required for WebSocket connection")
-		http.Error(w, "User ID is required", http.StatusBadRequest)
+	if err != nil {
+		log.Printf("Websocket upgrade failed: %v", err)
 		return
 	}
 
-	packageSlug := r.URL.Query().Get("packageSlug")
+	defer conn.Close()
 
-	if packageSlug == "" {
-		log.Println("Package slug is required for WebSocket connection")
-		http.Error(w, "Package slug is required", http.StatusBadRequest)
-		return
-	}
+	userID := identity.UserID
 
 	connManager.Add(userID, conn)
 
-	ctx := r.Context()
+	ctx := auth.WithIdentity(r.Context(), identity)
 
 	driverService, err := grpc_clients.NewDriverServiceClient()
 	if err != nil {
@@ -97,15 +99,20 @@ func handleDriversWebSocket(w http.ResponseWriter, r *http.Request, rb *messagin
 	}
 
 	defer func() {
-		connManager.Remove(userID)
+		defer driverService.Close()
+
+		// a newer connection of the driver replaced this one and keeps them registered
+		if !connManager.Remove(userID, conn) {
+			log.Printf("Driver %s reconnected, keeping them registered", userID)
+			return
+		}
+
 		driverService.Client.UnregisterDriver(ctx, &driverGrpc.RegisterDriverRequest{
 			DriverID:    userID,
 			PackageSlug: packageSlug,
 		})
 
 		log.Printf("Driver %s unregistered successfully", userID)
-
-		driverService.Close()
 	}()
 
 	driverData, err := driverService.Client.RegisterDriver(ctx, &driverGrpc.RegisterDriverRequest{
@@ -127,20 +134,8 @@ func handleDriversWebSocket(w http.ResponseWriter, r *http.Request, rb *messagin
 		return
 	}
 
-	// initialize queue consumers
-	queues := []string{
-		messaging.DriverCmdTripRequestQueue,
-	}
-
-	// start queue consumers for the driver
-	for _, q := range queues {
-		consumer := messaging.NewQueueConsumer(rb, connManager, q)
-		if err := consumer.Start(); err != nil {
-			log.Printf("Error starting consumer for queue %s: %v", q, err)
-		}
-	}
-
 	// reading messages from the driver from its ws connection.
+	// the read also fails once the driver stops answering pings, which unregisters them.
 	for {
 		_, message, err := conn.ReadMessage()
 
@@ -149,33 +144,107 @@ func handleDriversWebSocket(w http.ResponseWriter, r *http.Request, rb *messagin
 			break
 		}
 
-		type driverMessage struct {
-			Type string          `json:"type"`
-			Data json.RawMessage `json:"data"`
-		}
-
-		var driverMsg driverMessage
+		var driverMsg contracts.WSDriverMessage
 
 		if err := json.Unmarshal(message, &driverMsg); err != nil {
 			log.Printf("Error unmarshalling driver message: %v", err)
+			sendError(userID, "", contracts.WSErrorBadRequest, "invalid message")
 			continue
 		}
 
 		switch driverMsg.Type {
 		case contracts.DriverCmdLocation:
-			continue
+			var location driverLocationMessage
+			if err := json.Unmarshal(driverMsg.Data, &location); err != nil {
+				log.Printf("Error unmarshalling driver location: %v", err)
+				sendError(userID, driverMsg.ID, contracts.WSErrorBadRequest, "invalid location")
+				continue
+			}
+
+			if _, err := driverService.Client.UpdateLocation(ctx, location.toProto(userID)); err != nil {
+				log.Printf("Error updating location of driver %s: %v", userID, err)
+				sendError(userID, driverMsg.ID, contracts.WSErrorInternal, "failed to update location")
+				continue
+			}
 		case contracts.DriverCmdTripAccept, contracts.DriverCmdTripDecline:
+			var response messaging.DriverTripResponseData
+			if err := json.Unmarshal(driverMsg.Data, &response); err != nil {
+				log.Printf("Error unmarshalling trip response: %v", err)
+				sendError(userID, driverMsg.ID, contracts.WSErrorBadRequest, "invalid trip response")
+				continue
+			}
+
+			// the driver is the authenticated one, whatever the client sent
+			driver := driverData.Driver
+			if driverMsg.Type == contracts.DriverCmdTripAccept {
+				claimed, ok := claimTrip(ctx, driverService.Client, connManager, userID, response.TripID)
+				if !ok {
+					sendError(userID, driverMsg.ID, contracts.WSErrorTripUnavailable, "the trip is no longer offered to you")
+					continue
+				}
+				driver = claimed
+			}
+
+			payload := messaging.DriverTripResponseData{
+				TripID:  response.TripID,
+				RiderID: response.RiderID,
+			}
+			proto.Merge(&payload.Driver, driver)
+
+			data, err := json.Marshal(&payload)
+			if err != nil {
+				log.Printf("Error marshalling trip response: %v", err)
+				sendError(userID, driverMsg.ID, contracts.WSErrorInternal, "failed to forward the trip response")
+				continue
+			}
+
 			// forward the message to queue
 			if err := rb.PublishMessage(ctx, driverMsg.Type, &contracts.AmqpMessage{
 				OwnerID: userID,
-				Data:    driverMsg.Data,
+				Data:    data,
 			}); err != nil {
 				log.Printf("Error publishing message to RabbitMQ: %v", err)
+				sendError(userID, driverMsg.ID, contracts.WSErrorInternal, "failed to forward the trip response")
+				continue
 			}
 		default:
 			log.Printf("Unknown driver command type: %s", driverMsg.Type)
+			sendError(userID, driverMsg.ID, contracts.WSErrorUnknownType, "unknown message type "+driverMsg.Type)
+			continue
 		}
 
-		log.Printf("Received message from rider %s: %s", userID, message)
+		if err := connManager.SendAck(userID, driverMsg.ID); err != nil {
+			log.Printf("Error acking message of driver %s: %v", userID, err)
+		}
+	}
+}
+
+// sendError rejects a client message, logging when the client cannot be told.
+func sendError(userID, messageID, code, message string) {
+	if err := connManager.SendError(userID, messageID, code, message); err != nil {
+		log.Printf("Error sending error to user %s: %v", userID, err)
 	}
 }
+
+// claimTrip reserves the offered trip for the driver before their accept is forwarded,
+// so that a trip can never be accepted by a driver who no longer holds its offer.
+// It returns the driver record that the trip is assigned to.
+func claimTrip(ctx context.Context, client driverGrpc.DriverServiceClient, connManager *messaging.ConnectionManager, userID, tripID string) (*driverGrpc.Driver, bool) {
+	claim, err := client.ClaimTrip(ctx, &driverGrpc.ClaimTripRequest{
+		DriverID: userID,
+		TripID:   tripID,
+	})
+	if err != nil {
+		log.Printf("Driver %s could not claim trip %s: %v", userID, tripID, err)
+
+		if err := connManager.SendMessage(userID, contracts.WSMessage{
+			Type: contracts.DriverEventTripUnavailable,
+			Data: map[string]string{"tripID": tripID},
+		}); err != nil {
+			log.Printf("Error sending message to driver %s: %v", userID, err)
+		}
+		return nil, false
+	}
+
+	return claim.GetDriver(), true
+}
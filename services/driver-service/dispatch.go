@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+	pb "ride-sharing/shared/proto/trip"
+	"ride-sharing/shared/types"
+)
+
+type offerOutcome string
+
+const (
+	offerPending  offerOutcome = "pending"
+	offerDeclined offerOutcome = "declined"
+	offerTimedOut offerOutcome = "timed_out"
+)
+
+type offer struct {
+	DriverID  string
+	OfferedAt time.Time
+	Outcome   offerOutcome
+}
+
+// tripDispatch is the offer history of a trip that is still looking for a driver.
+type tripDispatch struct {
+	trip      *pb.Trip
+	pickup    *types.Coordinate
+	startedAt time.Time
+	offers    []*offer
+	timer     stopper
+}
+
+func (d *tripDispatch) excluded() map[string]bool {
+	excluded := make(map[string]bool, len(d.offers))
+	for _, o := range d.offers {
+		excluded[o.DriverID] = true
+	}
+	return excluded
+}
+
+func (d *tripDispatch) current() *offer {
+	if len(d.offers) == 0 {
+		return nil
+	}
+	return d.offers[len(d.offers)-1]
+}
+
+// publisher sends the offers and outcomes of dispatch, *messaging.RabbitMQ outside of tests.
+type publisher interface {
+	PublishMessage(ctx context.Context, routingKey string, message *contracts.AmqpMessage) error
+}
+
+// clock tells the time and runs the offer timeouts, so that tests can move time forward.
+type clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) stopper
+}
+
+type stopper interface {
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) stopper { return time.AfterFunc(d, f) }
+
+// dispatcher offers a trip to one driver at a time, closest first. Drivers who declined
+// or let the offer expire are not asked again, and after MaxAttempts offers or once the
+// Deadline has passed the rider is told that no drivers were found.
+type dispatcher struct {
+	publisher publisher
+	service   *Service
+	config    DispatchConfig
+	clock     clock
+
+	mu    sync.Mutex
+	trips map[string]*tripDispatch
+}
+
+func NewDispatcher(publisher publisher, service *Service, config DispatchConfig) *dispatcher {
+	return &dispatcher{
+		publisher: publisher,
+		service:   service,
+		config:    config,
+		clock:     systemClock{},
+		trips:     make(map[string]*tripDispatch),
+	}
+}
+
+// Start begins dispatching a newly created trip.
+func (d *dispatcher) Start(ctx context.Context, trip *pb.Trip) error {
+	pickup, ok := tripPickup(trip)
+	if !ok {
+		return fmt.Errorf("trip %s has no route to take the pickup location from", trip.Id)
+	}
+
+	d.mu.Lock()
+	if _, exists := d.trips[trip.Id]; exists {
+		d.mu.Unlock()
+		log.Printf("Trip %s is already being dispatched", trip.Id)
+		return nil
+	}
+
+	state := &tripDispatch{
+		trip:      trip,
+		pickup:    pickup,
+		startedAt: d.clock.Now(),
+	}
+	d.trips[trip.Id] = state
+	d.mu.Unlock()
+
+	return d.offerNext(ctx, trip.Id)
+}
+
+// Declined records the driver's refusal and moves on to the next driver.
+func (d *dispatcher) Declined(ctx context.Context, trip *pb.Trip, driverID string) error {
+	d.mu.Lock()
+	state, ok := d.trips[trip.Id]
+	if !ok {
+		// the history is gone (e.g. the service restarted), start over without this driver
+		pickup, found := tripPickup(trip)
+		if !found {
+			d.mu.Unlock()
+			return fmt.Errorf("trip %s has no route to take the pickup location from", trip.Id)
+		}
+
+		state = &tripDispatch{
+			trip:      trip,
+			pickup:    pickup,
+			startedAt: d.clock.Now(),
+			offers:    []*offer{{DriverID: driverID, OfferedAt: d.clock.Now(), Outcome: offerDeclined}},
+		}
+		d.trips[trip.Id] = state
+	} else {
+		current := state.current()
+		if current == nil || current.DriverID != driverID || current.Outcome != offerPending {
+			d.mu.Unlock()
+			log.Printf("Ignoring decline of trip %s from driver %s without a pending offer", trip.Id, driverID)
+			return nil
+		}
+
+		current.Outcome = offerDeclined
+		state.timer.Stop()
+	}
+	d.mu.Unlock()
+
+	d.service.ReleaseOffer(driverID, trip.Id)
+
+	return d.offerNext(ctx, trip.Id)
+}
+
+// Assigned forgets the trip once a driver has claimed it.
+func (d *dispatcher) Assigned(tripID string) {
+	d.stop(tripID)
+}
+
+// Cancelled forgets the trip and frees the driver currently holding its offer.
+func (d *dispatcher) Cancelled(tripID string) {
+	if current := d.stop(tripID); current != nil {
+		d.service.ReleaseTrip(current.DriverID, tripID)
+	}
+}
+
+func (d *dispatcher) stop(tripID string) *offer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state, ok := d.trips[tripID]
+	if !ok {
+		return nil
+	}
+
+	if state.timer != nil {
+		state.timer.Stop()
+	}
+
+	delete(d.trips, tripID)
+	return state.current()
+}
+
+func (d *dispatcher) expire(tripID, driverID string) {
+	d.mu.Lock()
+	state, ok := d.trips[tripID]
+	if !ok {
+		d.mu.Unlock()
+		return
+	}
+
+	current := state.current()
+	if current == nil || current.DriverID != driverID || current.Outcome != offerPending {
+		d.mu.Unlock()
+		return
+	}
+
+	// the driver may have claimed the trip right before the timer fired
+	if !d.service.ReleaseOffer(driverID, tripID) {
+		d.mu.Unlock()
+		log.Printf("Offer of trip %s to driver %s was claimed before it timed out", tripID, driverID)
+		return
+	}
+
+	current.Outcome = offerTimedOut
+	d.mu.Unlock()
+
+	log.Printf("Offer of trip %s to driver %s timed out", tripID, driverID)
+
+	if err := d.offerNext(context.Background(), tripID); err != nil {
+		log.Printf("failed to dispatch trip %s: %v", tripID, err)
+	}
+}
+
+// offerNext reserves the closest available driver that has not been asked yet and sends
+// them the trip, or gives up when attempts, time or drivers have run out.
+func (d *dispatcher) offerNext(ctx context.Context, tripID string) error {
+	d.mu.Lock()
+	state, ok := d.trips[tripID]
+	if !ok {
+		d.mu.Unlock()
+		return nil
+	}
+
+	trip := state.trip
+	remaining := d.config.Deadline - d.clock.Now().Sub(state.startedAt)
+
+	var driverID string
+	var offered bool
+	if len(state.offers) < d.config.MaxAttempts && remaining > 0 {
+		driverID, offered = d.service.OfferTrip(tripID, state.pickup, trip.SelectedFare.GetPackageSlug(), state.excluded())
+	}
+
+	if !offered {
+		delete(d.trips, tripID)
+		attempts := len(state.offers)
+		d.mu.Unlock()
+
+		log.Printf("No suitable drivers found for trip %s after %d offers", tripID, attempts)
+		return d.publishNoDriversFound(ctx, trip)
+	}
+
+	timeout := d.config.OfferTimeout
+	if remaining < timeout {
+		timeout = remaining
+	}
+
+	state.offers = append(state.offers, &offer{DriverID: driverID, OfferedAt: d.clock.Now(), Outcome: offerPending})
+	state.timer = d.clock.AfterFunc(timeout, func() {
+		d.expire(tripID, driverID)
+	})
+	d.mu.Unlock()
+
+	log.Printf("Offering trip %s to driver %s", tripID, driverID)
+	return d.publishTripRequest(ctx, trip, driverID)
+}
+
+func (d *dispatcher) publishTripRequest(ctx context.Context, trip *pb.Trip, driverID string) error {
+	marshaledData, err := json.Marshal(trip)
+
+	if err != nil {
+		log.Printf("failed to marshal trip data: %v", err)
+		return err
+	}
+
+	if err := d.publisher.PublishMessage(ctx, contracts.DriverCmdTripRequest, &contracts.AmqpMessage{
+		OwnerID: driverID,
+		Data:    marshaledData,
+	}); err != nil {
+		log.Printf("failed to publish message: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *dispatcher) publishNoDriversFound(ctx context.Context, trip *pb.Trip) error {
+	marshaledData, err := json.Marshal(messaging.TripEventData{Trip: trip})
+
+	if err != nil {
+		log.Printf("failed to marshal trip data: %v", err)
+		return err
+	}
+
+	if err := d.publisher.PublishMessage(ctx, contracts.TripEventNoDriversFound, &contracts.AmqpMessage{
+		OwnerID: trip.UserID,
+		Data:    marshaledData,
+	}); err != nil {
+		log.Printf("failed to publish message: %v", err)
+		return err
+	}
+
+	return nil
+}
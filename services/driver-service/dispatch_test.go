@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"ride-sharing/shared/contracts"
+	pb "ride-sharing/shared/proto/driver"
+	tripPb "ride-sharing/shared/proto/trip"
+)
+
+// fakeClock only moves when told to, firing the timers that came due on the way.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) stopper {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at.Before(c.timers[j].at) })
+
+		var due *fakeTimer
+		for i, t := range c.timers {
+			if !t.at.After(end) {
+				due = t
+				c.timers = append(c.timers[:i], c.timers[i+1:]...)
+				break
+			}
+		}
+		if due == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+
+		c.now = due.at
+		c.mu.Unlock()
+
+		// timers fire outside the lock, like time.AfterFunc, so they can start new ones
+		if !due.stopped {
+			due.stopped = true
+			due.f()
+		}
+	}
+}
+
+// recordingPublisher keeps "routing key -> owner" for every published message.
+type recordingPublisher struct {
+	mu        sync.Mutex
+	published []string
+}
+
+func (p *recordingPublisher) PublishMessage(ctx context.Context, routingKey string, message *contracts.AmqpMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.published = append(p.published, routingKey+" -> "+message.OwnerID)
+	return nil
+}
+
+const dispatchTripID = "trip-1"
+
+func offerTo(driverID string) string { return contracts.DriverCmdTripRequest + " -> " + driverID }
+
+var noDrivers = contracts.TripEventNoDriversFound + " -> rider-1"
+
+type dispatchHarness struct {
+	ctx        context.Context
+	clock      *fakeClock
+	publisher  *recordingPublisher
+	service    *Service
+	dispatcher *dispatcher
+	trip       *tripPb.Trip
+}
+
+// newDispatchHarness registers three sedan drivers 100m, 1km and 2km north of the pickup.
+func newDispatchHarness(config DispatchConfig) *dispatchHarness {
+	h := &dispatchHarness{
+		ctx:       context.Background(),
+		clock:     &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)},
+		publisher: &recordingPublisher{},
+		service:   NewService(ServiceConfig{GeohashPrecision: 6, SearchRadius: 5000}),
+	}
+
+	h.dispatcher = NewDispatcher(h.publisher, h.service, config)
+	h.dispatcher.clock = h.clock
+
+	for id, offset := range map[string]float64{"near": 0.0009, "middle": 0.009, "far": 0.018} {
+		h.service.drivers.Upsert(&driverInMap{
+			Driver: &pb.Driver{Id: id, PackageSlug: "sedan", Location: &pb.Location{Latitude: centerLat + offset, Longitude: centerLng}},
+			Status: driverAvailable,
+		})
+	}
+
+	h.trip = &tripPb.Trip{
+		Id:           dispatchTripID,
+		UserID:       "rider-1",
+		SelectedFare: &tripPb.RideFare{PackageSlug: "sedan"},
+		Route: &tripPb.Route{Geometry: []*tripPb.Geometry{{
+			Coordinates: []*tripPb.Coordinate{{Latitude: centerLat, Longitude: centerLng}},
+		}}},
+	}
+
+	return h
+}
+
+func (h *dispatchHarness) status(driverID string) driverStatus {
+	d, _ := h.service.drivers.Get(driverID)
+	return d.Status
+}
+
+func TestDispatcher(t *testing.T) {
+	config := DispatchConfig{OfferTimeout: 15 * time.Second, MaxAttempts: 3, Deadline: 2 * time.Minute}
+
+	tests := []struct {
+		name   string
+		config DispatchConfig
+		// run drives the dispatch of the trip, which has already been started
+		run        func(t *testing.T, h *dispatchHarness)
+		published  []string
+		wantStatus map[string]driverStatus
+	}{
+		{
+			name:       "offers the closest driver first",
+			config:     config,
+			run:        func(t *testing.T, h *dispatchHarness) {},
+			published:  []string{offerTo("near")},
+			wantStatus: map[string]driverStatus{"near": driverOffered, "middle": driverAvailable},
+		},
+		{
+			name:   "moves on after a decline",
+			config: config,
+			run: func(t *testing.T, h *dispatchHarness) {
+				if err := h.dispatcher.Declined(h.ctx, h.trip, "near"); err != nil {
+					t.Fatalf("Declined() = %v", err)
+				}
+			},
+			published:  []string{offerTo("near"), offerTo("middle")},
+			wantStatus: map[string]driverStatus{"near": driverAvailable, "middle": driverOffered},
+		},
+		{
+			name:   "moves on after a timeout",
+			config: config,
+			run: func(t *testing.T, h *dispatchHarness) {
+				h.clock.Advance(config.OfferTimeout)
+			},
+			published:  []string{offerTo("near"), offerTo("middle")},
+			wantStatus: map[string]driverStatus{"near": driverAvailable, "middle": driverOffered},
+		},
+		{
+			name:   "never asks a driver twice",
+			config: DispatchConfig{OfferTimeout: 15 * time.Second, MaxAttempts: 10, Deadline: 2 * time.Minute},
+			run: func(t *testing.T, h *dispatchHarness) {
+				h.clock.Advance(config.OfferTimeout)
+				if err := h.dispatcher.Declined(h.ctx, h.trip, "middle"); err != nil {
+					t.Fatalf("Declined() = %v", err)
+				}
+				h.clock.Advance(config.OfferTimeout)
+			},
+			published:  []string{offerTo("near"), offerTo("middle"), offerTo("far"), noDrivers},
+			wantStatus: map[string]driverStatus{"near": driverAvailable, "middle": driverAvailable, "far": driverAvailable},
+		},
+		{
+			name:   "gives up after the last attempt",
+			config: DispatchConfig{OfferTimeout: 15 * time.Second, MaxAttempts: 2, Deadline: 2 * time.Minute},
+			run: func(t *testing.T, h *dispatchHarness) {
+				h.clock.Advance(2 * config.OfferTimeout)
+			},
+			published:  []string{offerTo("near"), offerTo("middle"), noDrivers},
+			wantStatus: map[string]driverStatus{"near": driverAvailable, "middle": driverAvailable, "far": driverAvailable},
+		},
+		{
+			name:   "gives up at the deadline",
+			config: DispatchConfig{OfferTimeout: 15 * time.Second, MaxAttempts: 3, Deadline: 20 * time.Second},
+			run: func(t *testing.T, h *dispatchHarness) {
+				// the second offer only gets what is left of the deadline
+				h.clock.Advance(20 * time.Second)
+			},
+			published:  []string{offerTo("near"), offerTo("middle"), noDrivers},
+			wantStatus: map[string]driverStatus{"near": driverAvailable, "middle": driverAvailable},
+		},
+		{
+			name:   "cancelled while an offer is pending",
+			config: config,
+			run: func(t *testing.T, h *dispatchHarness) {
+				h.dispatcher.Cancelled(dispatchTripID)
+				h.clock.Advance(config.Deadline)
+			},
+			published:  []string{offerTo("near")},
+			wantStatus: map[string]driverStatus{"near": driverAvailable, "middle": driverAvailable},
+		},
+		{
+			name:   "claimed right before the offer times out",
+			config: config,
+			run: func(t *testing.T, h *dispatchHarness) {
+				if _, err := h.service.ClaimTrip("near", dispatchTripID); err != nil {
+					t.Fatalf("ClaimTrip() = %v", err)
+				}
+				h.clock.Advance(config.OfferTimeout)
+			},
+			published:  []string{offerTo("near")},
+			wantStatus: map[string]driverStatus{"near": driverOnTrip, "middle": driverAvailable},
+		},
+		{
+			name:   "ignores a decline from a driver without the offer",
+			config: config,
+			run: func(t *testing.T, h *dispatchHarness) {
+				if err := h.dispatcher.Declined(h.ctx, h.trip, "far"); err != nil {
+					t.Fatalf("Declined() = %v", err)
+				}
+			},
+			published:  []string{offerTo("near")},
+			wantStatus: map[string]driverStatus{"near": driverOffered, "far": driverAvailable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newDispatchHarness(tt.config)
+
+			if err := h.dispatcher.Start(h.ctx, h.trip); err != nil {
+				t.Fatalf("Start() = %v", err)
+			}
+			tt.run(t, h)
+
+			if len(h.publisher.published) != len(tt.published) {
+				t.Fatalf("published %v, want %v", h.publisher.published, tt.published)
+			}
+			for i := range tt.published {
+				if h.publisher.published[i] != tt.published[i] {
+					t.Fatalf("published %v, want %v", h.publisher.published, tt.published)
+				}
+			}
+
+			for driverID, want := range tt.wantStatus {
+				if got := h.status(driverID); got != want {
+					t.Errorf("driver %s is %s, want %s", driverID, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	pb "ride-sharing/shared/proto/driver"
+	"ride-sharing/shared/util"
+
+	"github.com/mmcloughlin/geohash"
+)
+
+const metersPerDegree = 111320
+
+// driverIndex buckets drivers by the geohash cell of their location so that a
+// search only has to look at the cells around the pickup instead of every driver.
+// It is not safe for concurrent use, the Service guards it with its mutex.
+type driverIndex struct {
+	precision uint
+	cells     map[string]map[string]*driverInMap // cell -> driverID -> driver
+	drivers   map[string]*driverInMap            // driverID -> driver
+}
+
+type nearbyDriver struct {
+	Driver   *pb.Driver
+	Distance float64 // in meters
+}
+
+func newDriverIndex(precision uint) *driverIndex {
+	return &driverIndex{
+		precision: precision,
+		cells:     make(map[string]map[string]*driverInMap),
+		drivers:   make(map[string]*driverInMap),
+	}
+}
+
+func (i *driverIndex) cellOf(loc *pb.Location) string {
+	return geohash.EncodeWithPrecision(loc.GetLatitude(), loc.GetLongitude(), i.precision)
+}
+
+// Upsert adds the driver or moves it to the cell of its current location.
+func (i *driverIndex) Upsert(d *driverInMap) {
+	i.Remove(d.Driver.Id)
+
+	d.cell = i.cellOf(d.Driver.Location)
+	if i.cells[d.cell] == nil {
+		i.cells[d.cell] = make(map[string]*driverInMap)
+	}
+
+	i.cells[d.cell][d.Driver.Id] = d
+	i.drivers[d.Driver.Id] = d
+}
+
+func (i *driverIndex) Remove(driverID string) {
+	d, ok := i.drivers[driverID]
+	if !ok {
+		return
+	}
+
+	delete(i.cells[d.cell], driverID)
+	if len(i.cells[d.cell]) == 0 {
+		delete(i.cells, d.cell)
+	}
+
+	delete(i.drivers, driverID)
+}
+
+func (i *driverIndex) Get(driverID string) (*driverInMap, bool) {
+	d, ok := i.drivers[driverID]
+	return d, ok
+}
+
+// InCell returns the drivers whose geohash starts with cell. A cell at least as precise as
+// the index is read from a single bucket, a coarser one from every bucket it contains.
+func (i *driverIndex) InCell(cell string) []*driverInMap {
+	var buckets []map[string]*driverInMap
+	if uint(len(cell)) >= i.precision {
+		buckets = append(buckets, i.cells[cell[:i.precision]])
+	} else {
+		for bucket, drivers := range i.cells {
+			if strings.HasPrefix(bucket, cell) {
+				buckets = append(buckets, drivers)
+			}
+		}
+	}
+
+	var found []*driverInMap
+	for _, drivers := range buckets {
+		for _, d := range drivers {
+			if strings.HasPrefix(d.Driver.Geohash, cell) {
+				found = append(found, d)
+			}
+		}
+	}
+
+	return found
+}
+
+// Nearby returns the drivers accepted by match within radius meters of the given point,
+// closest first. Cells are visited ring by ring around the point's cell and the search
+// stops as soon as the closest match is guaranteed to have been found, or once the
+// rings cover the whole radius.
+func (i *driverIndex) Nearby(lat, lng, radius float64, match func(*driverInMap) bool) []nearbyDriver {
+	center := geohash.EncodeWithPrecision(lat, lng, i.precision)
+
+	// the smallest side of a cell bounds how far each ring reaches
+	box := geohash.BoundingBox(center)
+	cellHeight := (box.MaxLat - box.MinLat) * metersPerDegree
+	cellWidth := (box.MaxLng - box.MinLng) * metersPerDegree * math.Cos(lat*math.Pi/180)
+	step := math.Min(cellHeight, cellWidth)
+	maxRings := int(math.Ceil(radius / step))
+
+	var found []nearbyDriver
+	visited := map[string]bool{center: true}
+	ring := []string{center}
+
+	for r := 0; r <= maxRings && len(ring) > 0; r++ {
+		for _, cell := range ring {
+			for _, d := range i.cells[cell] {
+				if !match(d) {
+					continue
+				}
+
+				loc := d.Driver.Location
+				distance := util.HaversineDistance(lat, lng, loc.GetLatitude(), loc.GetLongitude())
+				if distance > radius {
+					continue
+				}
+
+				found = append(found, nearbyDriver{Driver: d.Driver, Distance: distance})
+			}
+		}
+
+		sort.Slice(found, func(a, b int) bool {
+			return found[a].Distance < found[b].Distance
+		})
+
+		// every point closer than r cells has been visited already
+		if len(found) > 0 && found[0].Distance <= float64(r)*step {
+			break
+		}
+
+		var next []string
+		for _, cell := range ring {
+			for _, neighbor := range geohash.Neighbors(cell) {
+				if visited[neighbor] {
+					continue
+				}
+				visited[neighbor] = true
+				next = append(next, neighbor)
+			}
+		}
+		ring = next
+	}
+
+	return found
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	pb "ride-sharing/shared/proto/driver"
+	"ride-sharing/shared/util"
+)
+
+const (
+	centerLat = 52.52
+	centerLng = 13.405
+)
+
+func indexedDriver(id string, lat, lng float64, status driverStatus) *driverInMap {
+	return &driverInMap{
+		Driver: &pb.Driver{Id: id, Location: &pb.Location{Latitude: lat, Longitude: lng}},
+		Status: status,
+	}
+}
+
+func matchAll(*driverInMap) bool { return true }
+
+func TestNearby(t *testing.T) {
+	index := newDriverIndex(6)
+	index.Upsert(indexedDriver("100m", centerLat+0.0009, centerLng, driverAvailable))
+	index.Upsert(indexedDriver("1km", centerLat, centerLng+0.0147, driverAvailable))
+	index.Upsert(indexedDriver("5km", centerLat-0.045, centerLng, driverAvailable))
+	index.Upsert(indexedDriver("busy", centerLat+0.0001, centerLng, driverOnTrip))
+
+	available := func(d *driverInMap) bool { return d.Status == driverAvailable }
+	notNearest := func(d *driverInMap) bool { return available(d) && d.Driver.Id != "100m" }
+
+	tests := []struct {
+		name    string
+		radius  float64
+		match   func(*driverInMap) bool
+		closest string // empty when no driver should be found
+	}{
+		{"closest of all", 3000, matchAll, "busy"},
+		{"closest available", 3000, available, "100m"},
+		{"skips excluded drivers", 3000, notNearest, "1km"},
+		{"looks further out", 10000, func(d *driverInMap) bool { return d.Driver.Id == "5km" }, "5km"},
+		{"nobody within the radius", 4000, func(d *driverInMap) bool { return d.Driver.Id == "5km" }, ""},
+		{"nobody matches", 10000, func(*driverInMap) bool { return false }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := index.Nearby(centerLat, centerLng, tt.radius, tt.match)
+
+			if tt.closest == "" {
+				if len(found) != 0 {
+					t.Fatalf("Nearby() = %d drivers, want none", len(found))
+				}
+				return
+			}
+
+			if len(found) == 0 || found[0].Driver.Id != tt.closest {
+				t.Fatalf("Nearby() closest = %v, want %s", found, tt.closest)
+			}
+
+			for i, d := range found {
+				if d.Distance > tt.radius {
+					t.Errorf("driver %s is %.0fm away, outside the %.0fm radius", d.Driver.Id, d.Distance, tt.radius)
+				}
+				if i > 0 && d.Distance < found[i-1].Distance {
+					t.Errorf("drivers are not sorted by distance: %v", found)
+				}
+			}
+		})
+	}
+}
+
+// TestNearbyFindsTheClosestDriver compares Nearby with a scan of every driver, so that
+// drivers just across a cell border from the pickup are not missed.
+func TestNearbyFindsTheClosestDriver(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for run := 0; run < 200; run++ {
+		index := newDriverIndex(6)
+		for n := 0; n < 20; n++ {
+			lat := centerLat + (rng.Float64()-0.5)*0.1
+			lng := centerLng + (rng.Float64()-0.5)*0.1
+			index.Upsert(indexedDriver(fmt.Sprint(n), lat, lng, driverAvailable))
+		}
+
+		lat := centerLat + (rng.Float64()-0.5)*0.05
+		lng := centerLng + (rng.Float64()-0.5)*0.05
+		radius := 1000 + rng.Float64()*5000
+
+		closest, best := "", radius
+		for id, d := range index.drivers {
+			distance := util.HaversineDistance(lat, lng, d.Driver.Location.Latitude, d.Driver.Location.Longitude)
+			if distance <= best {
+				closest, best = id, distance
+			}
+		}
+
+		found := index.Nearby(lat, lng, radius, matchAll)
+		switch {
+		case closest == "" && len(found) != 0:
+			t.Fatalf("run %d: Nearby() found %s, want nobody within %.0fm", run, found[0].Driver.Id, radius)
+		case closest != "" && (len(found) == 0 || found[0].Driver.Id != closest):
+			t.Fatalf("run %d: Nearby() = %v, want %s at %.0fm first", run, found, closest, best)
+		}
+	}
+}
+
+func TestUpsertMovesTheDriver(t *testing.T) {
+	index := newDriverIndex(6)
+
+	d := indexedDriver("driver-1", centerLat, centerLng, driverAvailable)
+	index.Upsert(d)
+	first := d.cell
+
+	d.Driver.Location = &pb.Location{Latitude: centerLat + 0.05, Longitude: centerLng}
+	index.Upsert(d)
+
+	if _, ok := index.cells[first]; ok {
+		t.Errorf("cell %s still holds the driver after they moved", first)
+	}
+	if found := index.Nearby(centerLat, centerLng, 1000, matchAll); len(found) != 0 {
+		t.Errorf("Nearby() found the driver at their old location")
+	}
+
+	index.Remove("driver-1")
+	if len(index.cells) != 0 || len(index.drivers) != 0 {
+		t.Errorf("Remove() left %d cells and %d drivers", len(index.cells), len(index.drivers))
+	}
+}
@@ -2,6 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
+	"time"
+
+	"ride-sharing/shared/auth"
 	pb "ride-sharing/shared/proto/driver"
 
 	"google.golang.org/grpc"
@@ -11,12 +15,14 @@ import (
 
 type grpcHandler struct {
 	pb.UnimplementedDriverServiceServer
-	Service *Service
+	Service   *Service
+	publisher *locationPublisher
 }
 
-func NewGrpcHandler(s *grpc.Server, service *Service) *grpcHandler {
+func NewGrpcHandler(s *grpc.Server, service *Service, publisher *locationPublisher) *grpcHandler {
 	grpcHandler := &grpcHandler{
-		Service: service,
+		Service:   service,
+		publisher: publisher,
 	}
 
 	pb.RegisterDriverServiceServer(s, grpcHandler)
@@ -25,7 +31,12 @@ func NewGrpcHandler(s *grpc.Server, service *Service) *grpcHandler {
 }
 
 func (h *grpcHandler) RegisterDriver(ctx context.Context, req *pb.RegisterDriverRequest) (*pb.RegisterDriverResponse, error) {
-	driver, err := h.Service.RegisterDriver(req.GetDriverID(), req.GetPackageSlug())
+	driverID, err := auth.IncomingUserID(ctx, req.GetDriverID())
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := h.Service.RegisterDriver(driverID, req.GetPackageSlug())
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to register driver: %v", err)
 	}
@@ -34,10 +45,76 @@ func (h *grpcHandler) RegisterDriver(ctx context.Context, req *pb.RegisterDriver
 	}, nil
 }
 func (h *grpcHandler) UnregisterDriver(ctx context.Context, req *pb.RegisterDriverRequest) (*pb.RegisterDriverResponse, error) {
-	if req.GetDriverID() == "" {
-		return nil, status.Error(codes.InvalidArgument, "driver ID is required")
+	driverID, err := auth.IncomingUserID(ctx, req.GetDriverID())
+	if err != nil {
+		return nil, err
 	}
 
-	h.Service.UnregisterDriver(req.GetDriverID())
+	h.Service.UnregisterDriver(driverID)
 	return &pb.RegisterDriverResponse{}, nil
 }
+
+func (h *grpcHandler) UpdateLocation(ctx context.Context, req *pb.UpdateLocationRequest) (*pb.UpdateLocationResponse, error) {
+	if req.GetLocation() == nil {
+		return nil, status.Error(codes.InvalidArgument, "location is required")
+	}
+
+	driverID, err := auth.IncomingUserID(ctx, req.GetDriverID())
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := h.Service.UpdateLocation(driverID, req.GetLocation(), time.Now())
+	if err != nil {
+		if errors.Is(err, ErrDriverNotFound) {
+			return nil, status.Errorf(codes.NotFound, "failed to update location: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update location: %v", err)
+	}
+
+	if update.Notify {
+		if err := h.publisher.PublishLocationUpdated(ctx, update); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to publish location update: %v", err)
+		}
+	}
+
+	return &pb.UpdateLocationResponse{
+		Driver: update.Driver,
+	}, nil
+}
+
+func (h *grpcHandler) ClaimTrip(ctx context.Context, req *pb.ClaimTripRequest) (*pb.ClaimTripResponse, error) {
+	if req.GetTripID() == "" {
+		return nil, status.Error(codes.InvalidArgument, "trip ID is required")
+	}
+
+	driverID, err := auth.IncomingUserID(ctx, req.GetDriverID())
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := h.Service.ClaimTrip(driverID, req.GetTripID())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDriverNotFound):
+			return nil, status.Errorf(codes.NotFound, "failed to claim trip: %v", err)
+		case errors.Is(err, ErrDriverUnavailable):
+			return nil, status.Errorf(codes.FailedPrecondition, "failed to claim trip: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to claim trip: %v", err)
+	}
+
+	return &pb.ClaimTripResponse{
+		Driver: driver,
+	}, nil
+}
+
+func (h *grpcHandler) GetDriverSupply(ctx context.Context, req *pb.GetDriverSupplyRequest) (*pb.GetDriverSupplyResponse, error) {
+	if req.GetGeohash() == "" {
+		return nil, status.Error(codes.InvalidArgument, "geohash is required")
+	}
+
+	return &pb.GetDriverSupplyResponse{
+		AvailableDrivers: h.Service.AvailableDriversIn(req.GetGeohash()),
+	}, nil
+}
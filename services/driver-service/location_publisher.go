@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+)
+
+type locationPublisher struct {
+	rabbitMQ *messaging.RabbitMQ
+}
+
+func NewLocationPublisher(rabbitMQ *messaging.RabbitMQ) *locationPublisher {
+	return &locationPublisher{
+		rabbitMQ: rabbitMQ,
+	}
+}
+
+// PublishLocationUpdated sends the driver's new position to the rider of the assigned trip
+func (p *locationPublisher) PublishLocationUpdated(ctx context.Context, update *LocationUpdate) error {
+	data, err := json.Marshal(messaging.DriverLocationUpdatedData{
+		DriverID: update.Driver.Id,
+		TripID:   update.TripID,
+		Location: update.Driver.Location,
+		Geohash:  update.Driver.Geohash,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal location update: %w", err)
+	}
+
+	if err := p.rabbitMQ.PublishMessage(ctx, contracts.DriverEventLocationUpdated, &contracts.AmqpMessage{
+		OwnerID: update.RiderID,
+		Data:    data,
+	}); err != nil {
+		return fmt.Errorf("failed to publish location update: %w", err)
+	}
+
+	return nil
+}
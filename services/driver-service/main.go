@@ -10,6 +10,7 @@ import (
 	"ride-sharing/shared/messaging"
 	"ride-sharing/shared/tracing"
 	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
 )
@@ -51,18 +52,28 @@ func main() {
 	}
 
 	// rabbitmq connection
-	rabbitmq, err := messaging.NewRabbitMQ(rabbitMQURI)
+	rabbitmq, err := messaging.NewRabbitMQ(rabbitMQURI, messaging.NewRetryDefaultConfig())
 	if err != nil {
 		log.Fatalf("failed to connect to RabbitMQ: %v", err)
 	}
 	defer rabbitmq.Close()
 
-	service := NewService()
+	service := NewService(ServiceConfig{
+		GeohashPrecision: uint(env.GetInt("DRIVER_INDEX_GEOHASH_PRECISION", 6)),
+		SearchRadius:     float64(env.GetInt("DRIVER_SEARCH_RADIUS_METERS", 5000)),
+		LocationThrottle: time.Duration(env.GetInt("DRIVER_LOCATION_THROTTLE_MS", 1000)) * time.Millisecond,
+	})
 	// Starting gRPC server
 	grpcServer := grpc.NewServer(tracing.WithTracingInterceptors()...)
-	NewGrpcHandler(grpcServer, service)
+	NewGrpcHandler(grpcServer, service, NewLocationPublisher(rabbitmq))
 
-	consumer := NewTripConsumer(rabbitmq, service)
+	dispatcher := NewDispatcher(rabbitmq, service, DispatchConfig{
+		OfferTimeout: time.Duration(env.GetInt("DISPATCH_OFFER_TIMEOUT_SECONDS", 15)) * time.Second,
+		MaxAttempts:  env.GetInt("DISPATCH_MAX_ATTEMPTS", 5),
+		Deadline:     time.Duration(env.GetInt("DISPATCH_DEADLINE_SECONDS", 120)) * time.Second,
+	})
+
+	consumer := NewTripConsumer(rabbitmq, service, dispatcher)
 	go func() {
 		if err := consumer.Listen(); err != nil {
 			log.Fatalf("failed to listen for messages: %v", err)
@@ -1,53 +1,170 @@
 package main
 
 import (
+	"errors"
 	math "math/rand/v2"
 	pb "ride-sharing/shared/proto/driver"
+	"ride-sharing/shared/types"
 	"ride-sharing/shared/util"
 	"sync"
+	"time"
 
 	"github.com/mmcloughlin/geohash"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrDriverNotFound    = errors.New("driver not found")
+	ErrDriverUnavailable = errors.New("driver is not holding an offer for this trip")
+)
+
+type driverStatus string
+
+const (
+	driverAvailable driverStatus = "available"
+	driverOffered   driverStatus = "offered"
+	driverOnTrip    driverStatus = "on_trip"
+	driverOffline   driverStatus = "offline"
 )
 
 type Service struct {
-	drivers []*driverInMap
+	drivers *driverIndex
+	config  ServiceConfig
 	mu      sync.Mutex
 }
 
 type driverInMap struct {
 	Driver *pb.Driver
+	Status driverStatus
+
+	// trip the driver has been offered or assigned, and the rider waiting for them
+	TripID  string
+	RiderID string
+
+	cell               string // index cell the driver is bucketed in
+	lastLocationNotify time.Time
+}
+
+// LocationUpdate is the result of UpdateLocation. Notify is set when the assigned
+// rider should be sent the new position, which happens at most once per LocationThrottle.
+type LocationUpdate struct {
+	Driver  *pb.Driver
+	TripID  string
+	RiderID string
+	Notify  bool
 }
 
-func NewService() *Service {
+func NewService(config ServiceConfig) *Service {
 	return &Service{
-		drivers: make([]*driverInMap, 0),
+		drivers: newDriverIndex(config.GeohashPrecision),
+		config:  config,
 	}
 }
 
-func (s *Service) FindAvailableDrivers(packageType string) []string {
+// findAvailableDrivers returns the available drivers of the given package around the pickup,
+// skipping the excluded ones, closest first. The caller must hold s.mu.
+func (s *Service) findAvailableDrivers(pickup *types.Coordinate, packageType string, excluded map[string]bool) []nearbyDriver {
+	return s.drivers.Nearby(pickup.Latitude, pickup.Longitude, s.config.SearchRadius, func(d *driverInMap) bool {
+		return d.Status == driverAvailable && d.Driver.PackageSlug == packageType && !excluded[d.Driver.Id]
+	})
+}
 
-	var matchingDrivers []string
+// OfferTrip reserves the closest available driver for the trip so that no other trip
+// can be offered to them until they answer. It returns false when nobody is available.
+func (s *Service) OfferTrip(tripID string, pickup *types.Coordinate, packageType string, excluded map[string]bool) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	for _, driver := range s.drivers {
-		if driver.Driver.PackageSlug == packageType {
-			matchingDrivers = append(matchingDrivers, driver.Driver.Id)
-		}
+	nearby := s.findAvailableDrivers(pickup, packageType, excluded)
+	if len(nearby) == 0 {
+		return "", false
+	}
+
+	d, _ := s.drivers.Get(nearby[0].Driver.Id)
+	d.Status = driverOffered
+	d.TripID = tripID
+
+	return d.Driver.Id, true
+}
+
+// ReleaseOffer makes the driver available again if they are still holding the trip's offer.
+// It returns false when the offer has already been claimed or released.
+func (s *Service) ReleaseOffer(driverId, tripID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.drivers.Get(driverId)
+	if !ok || d.Status != driverOffered || d.TripID != tripID {
+		return false
+	}
+
+	d.Status = driverAvailable
+	d.TripID = ""
+
+	return true
+}
+
+// ClaimTrip atomically turns the driver's pending offer into a commitment to the trip.
+// It fails with ErrDriverUnavailable unless the driver holds the offer for this exact trip.
+func (s *Service) ClaimTrip(driverId, tripID string) (*pb.Driver, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.drivers.Get(driverId)
+	if !ok {
+		return nil, ErrDriverNotFound
 	}
 
-	if len(matchingDrivers) == 0 {
-		return nil
+	if d.TripID != tripID {
+		return nil, ErrDriverUnavailable
 	}
-	return matchingDrivers
+
+	switch d.Status {
+	case driverOffered:
+		d.Status = driverOnTrip
+	case driverOnTrip:
+		// the same accept delivered twice
+	default:
+		return nil, ErrDriverUnavailable
+	}
+
+	return proto.Clone(d.Driver).(*pb.Driver), nil
+}
+
+// AvailableDriversIn counts the available drivers of every package whose geohash starts with cell.
+func (s *Service) AvailableDriversIn(cell string) map[string]int32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int32)
+	for _, d := range s.drivers.InCell(cell) {
+		if d.Status == driverAvailable {
+			counts[d.Driver.PackageSlug]++
+		}
+	}
+
+	return counts
 }
 
 func (s *Service) RegisterDriver(driverId string, packageSlug string) (*pb.Driver, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// a driver reconnecting in the middle of a trip keeps their trip and position
+	if d, ok := s.drivers.Get(driverId); ok {
+		d.Driver.PackageSlug = packageSlug
+		if d.TripID != "" {
+			d.Status = driverOnTrip
+		} else {
+			d.Status = driverAvailable
+		}
+		return proto.Clone(d.Driver).(*pb.Driver), nil
+	}
+
 	randomIndex := math.IntN(len(PredefinedRoutes))
 	randomRoute := PredefinedRoutes[randomIndex]
 
-	// we can ignore this property for now, but it must be sent to the frontend.
+	// full precision hash sent to the frontend, the index buckets drivers by a shorter prefix
 	geohash := geohash.Encode(randomRoute[0][0], randomRoute[0][1])
 
 	randomPlate := GenerateRandomPlate()
@@ -63,36 +180,94 @@ func (s *Service) RegisterDriver(driverId string, packageSlug string) (*pb.Drive
 		CarPlate:       randomPlate,
 	}
 
-	s.drivers = append(s.drivers, &driverInMap{Driver: driver})
+	s.drivers.Upsert(&driverInMap{Driver: driver, Status: driverAvailable})
 	return driver, nil
 }
 
+// UnregisterDriver takes the driver offline. Drivers on a trip are kept so the trip
+// can still be tracked and released, everyone else is dropped from the index.
 func (s *Service) UnregisterDriver(driverId string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for i, driver := range s.drivers {
-		if driver.Driver.Id == driverId {
-			s.drivers = append(s.drivers[:i], s.drivers[i+1:]...)
-			break
-		}
+	d, ok := s.drivers.Get(driverId)
+	if !ok {
+		return
+	}
+
+	if d.Status == driverOnTrip {
+		d.Status = driverOffline
+		return
 	}
+
+	s.drivers.Remove(driverId)
 }
 
-/*
-append(s.drivers[:i], s.drivers[i+1:]...)
+// UpdateLocation moves the driver to its new position in the index.
+func (s *Service) UpdateLocation(driverId string, location *pb.Location, now time.Time) (*LocationUpdate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-we want to remove the item C
+	d, ok := s.drivers.Get(driverId)
+	if !ok {
+		return nil, ErrDriverNotFound
+	}
 
-[A,B,C,D,E,F]
+	d.Driver.Location = &pb.Location{Latitude: location.GetLatitude(), Longitude: location.GetLongitude()}
+	d.Driver.Geohash = geohash.Encode(location.GetLatitude(), location.GetLongitude())
+	s.drivers.Upsert(d)
 
-Step 1 - Get all of the elements before C
-s.drivers[:i] // [A,B]
+	update := &LocationUpdate{
+		Driver:  proto.Clone(d.Driver).(*pb.Driver),
+		TripID:  d.TripID,
+		RiderID: d.RiderID,
+	}
+
+	if d.RiderID != "" && now.Sub(d.lastLocationNotify) >= s.config.LocationThrottle {
+		d.lastLocationNotify = now
+		update.Notify = true
+	}
 
-Step 2 - Get all of the elements after C
-s.drivers[i+1:] // [D,E,F]
+	return update, nil
+}
 
-Step 3 - Append the two slices together
- append(s.drivers[:i], s.drivers[i+1:]...)
-[A,B] + [D,E,F] = [A,B,D,E,F]
-*/
+// AssignTrip records that the driver is now serving the rider's trip.
+func (s *Service) AssignTrip(driverId, tripID, riderID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.drivers.Get(driverId)
+	if !ok {
+		return ErrDriverNotFound
+	}
+
+	if d.Status != driverOffline {
+		d.Status = driverOnTrip
+	}
+	d.TripID = tripID
+	d.RiderID = riderID
+	d.lastLocationNotify = time.Time{}
+
+	return nil
+}
+
+// ReleaseTrip frees the driver if they are still offered or assigned the given trip,
+// which happens once the trip is paid or cancelled.
+func (s *Service) ReleaseTrip(driverId, tripID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.drivers.Get(driverId)
+	if !ok || d.TripID != tripID {
+		return
+	}
+
+	if d.Status == driverOffline {
+		s.drivers.Remove(driverId)
+		return
+	}
+
+	d.Status = driverAvailable
+	d.TripID = ""
+	d.RiderID = ""
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+	"ride-sharing/shared/types"
+
+	"github.com/mmcloughlin/geohash"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+func paymentDelivery(t *testing.T, routingKey string, payload messaging.PaymentStatusUpdateData) amqp091.Delivery {
+	t.Helper()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(contracts.AmqpMessage{OwnerID: payload.UserID, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return amqp091.Delivery{RoutingKey: routingKey, Body: body}
+}
+
+func TestDriverIsAvailableAgainOnceTheTripIsPaid(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(ServiceConfig{GeohashPrecision: 6, SearchRadius: 5000})
+	consumer := &tripConsumer{service: service}
+	pickup := &types.Coordinate{Latitude: centerLat, Longitude: centerLng}
+
+	d := indexedDriver("driver-1", centerLat, centerLng, driverAvailable)
+	d.Driver.PackageSlug = "sedan"
+	service.drivers.Upsert(d)
+
+	for _, tripID := range []string{"trip-1", "trip-2"} {
+		driverID, ok := service.OfferTrip(tripID, pickup, "sedan", nil)
+		if !ok || driverID != "driver-1" {
+			t.Fatalf("%s: OfferTrip() = %q, %v, want driver-1", tripID, driverID, ok)
+		}
+		if _, err := service.ClaimTrip("driver-1", tripID); err != nil {
+			t.Fatalf("%s: ClaimTrip() = %v", tripID, err)
+		}
+		if err := service.AssignTrip("driver-1", tripID, "rider-1"); err != nil {
+			t.Fatalf("%s: AssignTrip() = %v", tripID, err)
+		}
+
+		// reconnecting in the middle of the trip keeps the driver on it
+		if _, err := service.RegisterDriver("driver-1", "sedan"); err != nil {
+			t.Fatalf("%s: RegisterDriver() = %v", tripID, err)
+		}
+		if d, _ := service.drivers.Get("driver-1"); d.Status != driverOnTrip {
+			t.Fatalf("%s: driver is %s after reconnecting, want %s", tripID, d.Status, driverOnTrip)
+		}
+
+		err := consumer.handleTripPaid(ctx, paymentDelivery(t, contracts.PaymentEventSuccess, messaging.PaymentStatusUpdateData{
+			TripID:   tripID,
+			UserID:   "rider-1",
+			DriverID: "driver-1",
+		}))
+		if err != nil {
+			t.Fatalf("%s: handleTripPaid() = %v", tripID, err)
+		}
+
+		d, _ := service.drivers.Get("driver-1")
+		if d.Status != driverAvailable || d.TripID != "" || d.RiderID != "" {
+			t.Fatalf("%s: driver after payment = %s on %q for %q, want available", tripID, d.Status, d.TripID, d.RiderID)
+		}
+	}
+}
+
+func TestTripPaidIgnoresOtherTrips(t *testing.T) {
+	service := NewService(ServiceConfig{GeohashPrecision: 6, SearchRadius: 5000})
+	consumer := &tripConsumer{service: service}
+
+	service.drivers.Upsert(indexedDriver("driver-1", centerLat, centerLng, driverAvailable))
+	if err := service.AssignTrip("driver-1", "trip-2", "rider-2"); err != nil {
+		t.Fatalf("AssignTrip() = %v", err)
+	}
+
+	// a late payment of the driver's previous trip, and a cancellation fee
+	for _, payload := range []messaging.PaymentStatusUpdateData{
+		{TripID: "trip-1", UserID: "rider-1", DriverID: "driver-1"},
+		{TripID: "trip-2", UserID: "rider-2", DriverID: "driver-1", PaymentType: messaging.PaymentTypeCancellationFee},
+	} {
+		if err := consumer.handleTripPaid(context.Background(), paymentDelivery(t, contracts.PaymentEventSuccess, payload)); err != nil {
+			t.Fatalf("handleTripPaid() = %v", err)
+		}
+	}
+
+	if d, _ := service.drivers.Get("driver-1"); d.Status != driverOnTrip || d.TripID != "trip-2" {
+		t.Errorf("driver = %s on %q, want on_trip on trip-2", d.Status, d.TripID)
+	}
+}
+
+func TestAvailableDriversIn(t *testing.T) {
+	service := NewService(ServiceConfig{GeohashPrecision: 6, SearchRadius: 5000})
+
+	add := func(id string, lat float64, packageSlug string, status driverStatus) {
+		d := indexedDriver(id, lat, centerLng, status)
+		d.Driver.PackageSlug = packageSlug
+		d.Driver.Geohash = geohash.Encode(lat, centerLng)
+		service.drivers.Upsert(d)
+	}
+	add("sedan-1", centerLat, "sedan", driverAvailable)
+	add("sedan-2", centerLat, "sedan", driverAvailable)
+	add("van-1", centerLat, "van", driverAvailable)
+	add("busy", centerLat, "sedan", driverOnTrip)
+	add("elsewhere", centerLat+1, "sedan", driverAvailable)
+
+	center := geohash.Encode(centerLat, centerLng)
+
+	tests := []struct {
+		name string
+		cell string
+		want map[string]int32
+	}{
+		{"cell of the index", center[:6], map[string]int32{"sedan": 2, "van": 1}},
+		{"coarser cell", center[:4], map[string]int32{"sedan": 2, "van": 1}},
+		{"finer cell", center, map[string]int32{"sedan": 2, "van": 1}},
+		{"another cell", geohash.Encode(centerLat+1, centerLng)[:6], map[string]int32{"sedan": 1}},
+		{"cell without drivers", "u00000", map[string]int32{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := service.AvailableDriversIn(tt.cell)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("AvailableDriversIn(%s) = %v, want %v", tt.cell, got, tt.want)
+			}
+			for slug, n := range tt.want {
+				if got[slug] != n {
+					t.Errorf("AvailableDriversIn(%s)[%s] = %d, want %d", tt.cell, slug, got[slug], n)
+				}
+			}
+		})
+	}
+}
@@ -4,26 +4,41 @@ import (
 	"context"
 	"encoding/json"
 	"log"
-	"math/rand"
 	"ride-sharing/shared/contracts"
 	"ride-sharing/shared/messaging"
+	pb "ride-sharing/shared/proto/trip"
+	"ride-sharing/shared/types"
 
 	"github.com/rabbitmq/amqp091-go"
 )
 
 type tripConsumer struct {
-	rabbitMQ *messaging.RabbitMQ
-	service  *Service
+	rabbitMQ   *messaging.RabbitMQ
+	service    *Service
+	dispatcher *dispatcher
 }
 
-func NewTripConsumer(rabbitMQ *messaging.RabbitMQ, service *Service) *tripConsumer {
+func NewTripConsumer(rabbitMQ *messaging.RabbitMQ, service *Service, dispatcher *dispatcher) *tripConsumer {
 	return &tripConsumer{
-		rabbitMQ: rabbitMQ,
-		service:  service,
+		rabbitMQ:   rabbitMQ,
+		service:    service,
+		dispatcher: dispatcher,
 	}
 }
 
 func (c *tripConsumer) Listen() error {
+	if err := c.rabbitMQ.ConsumeMessages(messaging.DriverTripCancelledQueue, c.handleTripCancelled); err != nil {
+		return err
+	}
+
+	if err := c.rabbitMQ.ConsumeMessages(messaging.DriverTripAssignedQueue, c.handleTripAssigned); err != nil {
+		return err
+	}
+
+	if err := c.rabbitMQ.ConsumeMessages(messaging.DriverTripPaidQueue, c.handleTripPaid); err != nil {
+		return err
+	}
+
 	return c.rabbitMQ.ConsumeMessages(messaging.FindAvailableDriversQueue, func(ctx context.Context, msg amqp091.Delivery) error {
 		// Handle the incoming message
 		var tripEvent contracts.AmqpMessage
@@ -32,7 +47,8 @@ func (c *tripConsumer) Listen() error {
 			return err
 		}
 
-		var payload messaging.TripEventData
+		// driverID is only set on driver_not_interested events
+		var payload messaging.TripDriverNotInterestedData
 		if err := json.Unmarshal(tripEvent.Data, &payload); err != nil {
 			log.Printf("failed to unmarshal message: %v", err)
 			return err
@@ -41,8 +57,10 @@ func (c *tripConsumer) Listen() error {
 		log.Printf("Driver received message: %+v", payload)
 
 		switch msg.RoutingKey {
-		case contracts.TripEventCreated, contracts.TripEventDriverNotInterested:
-			return c.handleFindAndNotifyDrivers(ctx, &payload)
+		case contracts.TripEventCreated:
+			return c.dispatcher.Start(ctx, payload.Trip)
+		case contracts.TripEventDriverNotInterested:
+			return c.dispatcher.Declined(ctx, payload.Trip, payload.DriverID)
 		}
 
 		log.Printf("Unhandled routing key: %s", msg.RoutingKey)
@@ -50,39 +68,104 @@ func (c *tripConsumer) Listen() error {
 	})
 }
 
-func (c *tripConsumer) handleFindAndNotifyDrivers(ctx context.Context, payload *messaging.TripEventData) error {
+func (c *tripConsumer) handleTripCancelled(ctx context.Context, msg amqp091.Delivery) error {
+	var tripEvent contracts.AmqpMessage
+	if err := json.Unmarshal(msg.Body, &tripEvent); err != nil {
+		log.Printf("failed to unmarshal message: %v", err)
+		return err
+	}
+
+	var payload messaging.TripCancelledData
+	if err := json.Unmarshal(tripEvent.Data, &payload); err != nil {
+		log.Printf("failed to unmarshal message: %v", err)
+		return err
+	}
 
-	suitableIDs := c.service.FindAvailableDrivers(payload.Trip.SelectedFare.PackageSlug)
+	c.dispatcher.Cancelled(payload.Trip.GetId())
 
-	if len(suitableIDs) == 0 {
-		log.Printf("No suitable drivers found for trip %s", payload.Trip.Id)
+	driverID := payload.Trip.GetDriver().GetId()
 
-		if err := c.rabbitMQ.PublishMessage(ctx, contracts.TripEventNoDriversFound, &contracts.AmqpMessage{OwnerID: payload.Trip.UserID}); err != nil {
-			log.Printf("failed to publish message: %v", err)
-			return err
-		}
+	// nobody accepted the trip yet, so there is no driver to notify
+	if driverID == "" {
 		return nil
 	}
 
-	randomIndex := rand.Intn(len(suitableIDs))
-	suitableDriverID := suitableIDs[randomIndex]
+	c.service.ReleaseTrip(driverID, payload.Trip.GetId())
 
-	marshaledData, err := json.Marshal(payload.Trip)
+	log.Printf("Trip %s cancelled by %s, notifying driver %s", payload.Trip.GetId(), payload.CancelledBy, driverID)
 
-	if err != nil {
-		log.Printf("failed to marshal trip data: %v", err)
+	if err := c.rabbitMQ.PublishMessage(ctx, contracts.DriverEventTripCancelled, &contracts.AmqpMessage{
+		OwnerID: driverID,
+		Data:    tripEvent.Data,
+	}); err != nil {
+		log.Printf("failed to publish message: %v", err)
 		return err
 	}
 
-	log.Printf("Found suitable driver %s for trip %s", suitableDriverID, payload.Trip.Id)
+	return nil
+}
 
-	if err := c.rabbitMQ.PublishMessage(ctx, contracts.DriverCmdTripRequest, &contracts.AmqpMessage{
-		OwnerID: suitableDriverID,
-		Data:    marshaledData,
-	}); err != nil {
-		log.Printf("failed to publish message: %v", err)
+// tripPickup returns the first point of the trip route, which is where the rider is picked up
+func tripPickup(trip *pb.Trip) (*types.Coordinate, bool) {
+	for _, geometry := range trip.GetRoute().GetGeometry() {
+		if coords := geometry.GetCoordinates(); len(coords) > 0 {
+			return &types.Coordinate{
+				Latitude:  coords[0].GetLatitude(),
+				Longitude: coords[0].GetLongitude(),
+			}, true
+		}
+	}
+
+	return nil, false
+}
+
+// handleTripAssigned remembers which rider should receive the driver's location updates
+func (c *tripConsumer) handleTripAssigned(ctx context.Context, msg amqp091.Delivery) error {
+	var tripEvent contracts.AmqpMessage
+	if err := json.Unmarshal(msg.Body, &tripEvent); err != nil {
+		log.Printf("failed to unmarshal message: %v", err)
+		return err
+	}
+
+	var payload messaging.TripEventData
+	if err := json.Unmarshal(tripEvent.Data, &payload); err != nil {
+		log.Printf("failed to unmarshal message: %v", err)
 		return err
 	}
 
+	c.dispatcher.Assigned(payload.Trip.GetId())
+
+	driverID := payload.Trip.GetDriver().GetId()
+
+	if err := c.service.AssignTrip(driverID, payload.Trip.GetId(), payload.Trip.GetUserID()); err != nil {
+		// the driver may have gone offline in the meantime, there is nobody to track
+		log.Printf("failed to assign trip %s to driver %s: %v", payload.Trip.GetId(), driverID, err)
+	}
+
+	return nil
+}
+
+// handleTripPaid frees the driver of a paid trip so that they can be offered the next one
+func (c *tripConsumer) handleTripPaid(ctx context.Context, msg amqp091.Delivery) error {
+	var paymentEvent contracts.AmqpMessage
+	if err := json.Unmarshal(msg.Body, &paymentEvent); err != nil {
+		log.Printf("failed to unmarshal message: %v", err)
+		return err
+	}
+
+	var payload messaging.PaymentStatusUpdateData
+	if err := json.Unmarshal(paymentEvent.Data, &payload); err != nil {
+		log.Printf("failed to unmarshal message: %v", err)
+		return err
+	}
+
+	// the driver of a cancelled trip was released with the cancellation
+	if payload.PaymentType == messaging.PaymentTypeCancellationFee || payload.DriverID == "" {
+		return nil
+	}
+
+	c.service.ReleaseTrip(payload.DriverID, payload.TripID)
+
+	log.Printf("Trip %s paid, driver %s is available again", payload.TripID, payload.DriverID)
 	return nil
 }
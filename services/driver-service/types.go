@@ -1,5 +1,7 @@
 package main
 
+import "time"
+
 type Driver struct {
 	Id             string `json:"id"`
 	Name           string `json:"name"`
@@ -7,3 +9,15 @@ type Driver struct {
 	CarPlate       string `json:"carPlate"`
 	PackageSlug    string `json:"packageSlug"`
 }
+
+type ServiceConfig struct {
+	GeohashPrecision uint          // length of the geohash cells drivers are bucketed by
+	SearchRadius     float64       // in meters
+	LocationThrottle time.Duration // minimum interval between location updates sent to a rider
+}
+
+type DispatchConfig struct {
+	OfferTimeout time.Duration // how long a driver has to answer an offer
+	MaxAttempts  int           // offers made before giving up on a trip
+	Deadline     time.Duration // total time spent looking for a driver
+}
@@ -2,21 +2,37 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"ride-sharing/services/payment-service/internal/domain"
 	"ride-sharing/services/payment-service/internal/events"
+	"ride-sharing/services/payment-service/internal/infrastructure/fake"
+	grpcHandlers "ride-sharing/services/payment-service/internal/infrastructure/grpc"
+	"ride-sharing/services/payment-service/internal/infrastructure/repository"
 	"ride-sharing/services/payment-service/internal/infrastructure/stripe"
 	"ride-sharing/services/payment-service/internal/service"
 	"ride-sharing/services/payment-service/pkg/types"
+	"ride-sharing/shared/db"
 	"ride-sharing/shared/env"
 	"ride-sharing/shared/messaging"
 	"ride-sharing/shared/tracing"
+
+	"google.golang.org/grpc"
 )
 
-var GrpcAddr = env.GetString("GRPC_ADDR", ":9004")
+var (
+	GrpcAddr = env.GetString("GRPC_ADDR", ":9004")
+
+	// checkout page of the fake payment processor, and the address the browser reaches it at
+	FakeCheckoutAddr = env.GetString("FAKE_CHECKOUT_ADDR", ":9005")
+	FakeCheckoutURL  = env.GetString("FAKE_CHECKOUT_URL", "http://localhost:9005")
+)
 
 func main() {
 	tracerCfg := tracing.Config{
@@ -50,36 +66,114 @@ func main() {
 
 	// Stripe config
 	stripeCfg := &types.PaymentConfig{
-		StripeSecretKey: env.GetString("STRIPE_SECRET_KEY", ""),
-		SuccessURL:      env.GetString("STRIPE_SUCCESS_URL", appURL+"?payment=success"),
-		CancelURL:       env.GetString("STRIPE_CANCEL_URL", appURL+"?payment=cancel"),
+		StripeSecretKey:     env.GetString("STRIPE_SECRET_KEY", ""),
+		StripeWebhookSecret: env.GetString("STRIPE_WEBHOOK_KEY", ""),
+		SuccessURL:          env.GetString("STRIPE_SUCCESS_URL", appURL+"?payment=success"),
+		CancelURL:           env.GetString("STRIPE_CANCEL_URL", appURL+"?payment=cancel"),
 	}
 
-	if stripeCfg.StripeSecretKey == "" {
-		log.Fatalf("STRIPE_SECRET_KEY is not set")
-		return
+	// payment processor: stripe, or fake to pay on a local checkout page without Stripe
+	var paymentProcessor domain.PaymentProcessor
+	var fakeProcessor *fake.FakeProcessor
+
+	switch processor := env.GetString("PAYMENT_PROCESSOR", "stripe"); processor {
+	case "stripe":
+		if stripeCfg.StripeSecretKey == "" {
+			log.Fatalf("STRIPE_SECRET_KEY is not set")
+			return
+		}
+		paymentProcessor = stripe.NewStripeClient(stripeCfg)
+	case "fake":
+		fakeProcessor = fake.NewFakeProcessor(stripeCfg, FakeCheckoutURL)
+		paymentProcessor = fakeProcessor
+	default:
+		log.Fatalf("Unknown payment processor %q, use stripe or fake", processor)
 	}
 
-	// payment processor
-	paymentProcessor := stripe.NewStripeClient(stripeCfg)
-
-	// payment service
-	paymentService := service.NewPaymentService(paymentProcessor)
+	// payments are kept in MongoDB when it is configured, in memory otherwise
+	var paymentRepo domain.PaymentRepository = repository.NewInmemRepository()
+	var dedupeStore messaging.DedupeStore
+
+	if mongoCfg := db.NewMongoDefaultConfig(); mongoCfg.URI != "" {
+		mongoClient, err := db.NewMongoClient(ctx, mongoCfg)
+		if err != nil {
+			log.Fatalf("Failed to create MongoDB client: %v", err)
+		}
+		defer mongoClient.Disconnect(context.Background())
+
+		database := db.GetDatabase(mongoClient, mongoCfg)
+		paymentRepo = repository.NewMongoRepository(database)
+
+		// every instance of the service skips the messages any of them already processed
+		dedupeStore, err = messaging.NewMongoDedupeStore(ctx, database, db.ProcessedMessagesCollection, messaging.DefaultDedupeTTL)
+		if err != nil {
+			log.Fatalf("Failed to create dedupe store: %v", err)
+		}
+	}
 
 	// RabbitMQ connection
-	rabbitmq, err := messaging.NewRabbitMQ(rabbitMqURI)
+	rabbitmq, err := messaging.NewRabbitMQ(rabbitMqURI, messaging.NewRetryDefaultConfig())
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer rabbitmq.Close()
+	if dedupeStore != nil {
+		rabbitmq.SetDedupeStore(dedupeStore)
+	}
+
+	// payment service
+	paymentService := service.NewPaymentService(paymentProcessor, paymentRepo, events.NewPaymentEventPublisher(rabbitmq))
 
 	// Trip Consumer
 	tripConsumer := events.NewTripConsumer(rabbitmq, paymentService)
 	go tripConsumer.Listen()
 
+	// Payment consumer
+	paymentConsumer := events.NewPaymentConsumer(rabbitmq, paymentService)
+	go paymentConsumer.Listen()
+
 	log.Println("Starting RabbitMQ connection")
 
+	// Starting gRPC server
+	lis, err := net.Listen("tcp", GrpcAddr)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+
+	grpcServer := grpc.NewServer(tracing.WithTracingInterceptors()...)
+	grpcHandlers.NewGRPCHandler(grpcServer, paymentService)
+
+	log.Printf("Payment service is running on %s", lis.Addr().String())
+
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Printf("failed to serve: %v", err)
+			cancel()
+		}
+	}()
+
+	var checkoutServer *http.Server
+	if fakeProcessor != nil {
+		checkoutServer = &http.Server{
+			Addr:    FakeCheckoutAddr,
+			Handler: fake.NewCheckoutHandler(fakeProcessor, paymentService),
+		}
+
+		log.Printf("Fake checkout is running on %s, reachable at %s", FakeCheckoutAddr, FakeCheckoutURL)
+
+		go func() {
+			if err := checkoutServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("failed to serve the fake checkout: %v", err)
+				cancel()
+			}
+		}()
+	}
+
 	// Wait for shutdown signal
 	<-ctx.Done()
 	log.Println("Shutting down payment service...")
+	grpcServer.GracefulStop()
+	if checkoutServer != nil {
+		checkoutServer.Close()
+	}
 }
@@ -2,14 +2,107 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"ride-sharing/services/payment-service/pkg/types"
 )
 
+var (
+	ErrPaymentNotFound  = errors.New("payment not found")
+	ErrInvalidPageToken = errors.New("invalid page token")
+
+	ErrInvalidPaymentTransition = errors.New("invalid payment status transition")
+	// ErrPaymentStatusChanged is returned when the payment left the expected status
+	// between reading and updating it
+	ErrPaymentStatusChanged = errors.New("payment status changed concurrently")
+
+	// ErrPaymentNotRetryable is returned when the ride of a trip is paid, being paid or
+	// no longer owed, or when the rider already had all their attempts
+	ErrPaymentNotRetryable = errors.New("payment cannot be retried")
+
+	// ErrInvalidWebhook is returned for webhook deliveries that are not signed by the
+	// payment provider or cannot be parsed
+	ErrInvalidWebhook = errors.New("invalid webhook")
+
+	// ErrSessionNotOpen is returned when a checkout session cannot be expired because
+	// the rider already completed it
+	ErrSessionNotOpen = errors.New("checkout session is not open")
+)
+
+// InvalidTransitionError is returned when a payment is asked to move to a status
+// that is not reachable from its current one.
+type InvalidTransitionError struct {
+	PaymentID string
+	From      types.PaymentStatus
+	To        types.PaymentStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("payment %s cannot move from %q to %q", e.PaymentID, e.From, e.To)
+}
+
+func (e *InvalidTransitionError) Is(target error) bool {
+	return target == ErrInvalidPaymentTransition
+}
+
 type Service interface {
-	CreatePaymentSession(ctx context.Context, tripID, userID, driverID string, amount int64) (*types.PaymentIntent, error)
+	// CreatePaymentSession and ChargeCancellationFee take amounts in minor units of the currency.
+	CreatePaymentSession(ctx context.Context, tripID, userID, driverID string, amount int64, currency string) (*types.PaymentIntent, error)
+	ChargeCancellationFee(ctx context.Context, tripID, userID, driverID string, fee int64, currency string) (*types.PaymentIntent, error)
+
+	GetPayment(ctx context.Context, id string) (*types.Payment, error)
+	GetPaymentByTrip(ctx context.Context, tripID string) (*types.Payment, error)
+	ListPaymentsByUser(ctx context.Context, userID string, pageSize int, pageToken string) (*types.PaymentPage, error)
+
+	// UpdatePaymentStatusBySession settles the payment of a checkout session.
+	UpdatePaymentStatusBySession(ctx context.Context, sessionID string, status types.PaymentStatus) (*types.Payment, error)
+	// CancelPendingRidePayments cancels the unpaid ride payments of a cancelled trip,
+	// expiring their checkout sessions so that the rider can no longer pay them.
+	CancelPendingRidePayments(ctx context.Context, tripID string) error
+	// RefundPayment marks the successful payment of the given kind of a trip as refunded.
+	RefundPayment(ctx context.Context, tripID string, kind types.PaymentKind) (*types.Payment, error)
+	// RetryRidePayment opens a new checkout session for a ride whose last payment failed or expired.
+	RetryRidePayment(ctx context.Context, tripID string) (*types.PaymentIntent, error)
+
+	// HandleWebhook verifies a webhook delivery of the payment provider and publishes the
+	// payment event it reports, once per provider event however often it is delivered.
+	HandleWebhook(ctx context.Context, payload []byte, signature string) (eventID string, duplicate bool, err error)
 }
 
 type PaymentProcessor interface {
-	CreatePaymentSession(ctx context.Context, amount int64, metadata map[string]string) (string, error)
+	CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string) (*types.CheckoutSession, error)
+	// ParseWebhookEvent verifies the signature of a webhook delivery and translates it,
+	// failing with ErrInvalidWebhook when it is not genuine.
+	ParseWebhookEvent(payload []byte, signature string) (*types.WebhookEvent, error)
+	// ExpirePaymentSession closes an open checkout session so that it can no longer be paid.
+	// Expiring an expired session is a no-op, other sessions fail with ErrSessionNotOpen.
+	ExpirePaymentSession(ctx context.Context, sessionID string) error
+}
+
+// PaymentEventPublisher announces the outcome of payments to the other services.
+type PaymentEventPublisher interface {
+	PublishWebhookEvent(ctx context.Context, event *types.WebhookEvent) error
+}
+
+type PaymentRepository interface {
+	SavePayment(ctx context.Context, payment *types.Payment) error
+	// GetPayment and the other getters return ErrPaymentNotFound when nothing matches.
+	GetPayment(ctx context.Context, id string) (*types.Payment, error)
+	GetPaymentBySession(ctx context.Context, sessionID string) (*types.Payment, error)
+	// GetLatestPaymentByTrip returns the most recently created payment of the trip.
+	GetLatestPaymentByTrip(ctx context.Context, tripID string) (*types.Payment, error)
+	ListPaymentsByTrip(ctx context.Context, tripID string) ([]*types.Payment, error)
+	// ListPaymentsByUser returns the payments of the user, newest first.
+	ListPaymentsByUser(ctx context.Context, userID string, offset, limit int) ([]*types.Payment, error)
+	// UpdatePaymentStatus moves the payment from one status to another, failing with
+	// ErrPaymentStatusChanged when it is no longer in the from status.
+	UpdatePaymentStatus(ctx context.Context, id string, from, to types.PaymentStatus, at time.Time) error
+
+	// ClaimWebhookEvent records the event unless it is already, telling whether this call
+	// recorded it. Of deliveries racing each other, exactly one gets the claim.
+	ClaimWebhookEvent(ctx context.Context, eventID string, at time.Time) (bool, error)
+	// ReleaseWebhookEvent forgets a claimed event, so that a later delivery can claim it.
+	ReleaseWebhookEvent(ctx context.Context, eventID string) error
 }
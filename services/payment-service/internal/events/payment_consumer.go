@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// PaymentConsumer records the outcome of checkout sessions reported by the payment provider.
+type PaymentConsumer struct {
+	rabbitmq *messaging.RabbitMQ
+	service  domain.Service
+}
+
+func NewPaymentConsumer(rabbitmq *messaging.RabbitMQ, service domain.Service) *PaymentConsumer {
+	return &PaymentConsumer{
+		rabbitmq: rabbitmq,
+		service:  service,
+	}
+}
+
+func (c *PaymentConsumer) Listen() error {
+	return c.rabbitmq.ConsumeMessages(messaging.PaymentStatusUpdateQueue, func(ctx context.Context, msg amqp091.Delivery) error {
+		var message contracts.AmqpMessage
+		if err := json.Unmarshal(msg.Body, &message); err != nil {
+			log.Printf("Failed to unmarshal message: %v", err)
+			return err
+		}
+
+		var payload messaging.PaymentStatusUpdateData
+		if err := json.Unmarshal(message.Data, &payload); err != nil {
+			log.Printf("Failed to unmarshal payload: %v", err)
+			return err
+		}
+
+		if msg.RoutingKey == contracts.PaymentEventRefunded {
+			return c.handleRefund(ctx, payload)
+		}
+
+		var status types.PaymentStatus
+		switch msg.RoutingKey {
+		case contracts.PaymentEventSuccess:
+			status = types.PaymentStatusSuccess
+		case contracts.PaymentEventFailed:
+			status = types.PaymentStatusFailed
+		case contracts.PaymentEventCancelled:
+			status = types.PaymentStatusExpired
+		default:
+			return nil
+		}
+
+		payment, err := c.service.UpdatePaymentStatusBySession(ctx, payload.SessionID, status)
+		if err != nil {
+			if errors.Is(err, domain.ErrPaymentNotFound) || errors.Is(err, domain.ErrInvalidPaymentTransition) {
+				log.Printf("Ignoring payment event for session %s: %v", payload.SessionID, err)
+				return nil
+			}
+			return err
+		}
+
+		log.Printf("Payment %s of trip %s is now %s", payment.ID, payment.TripID, payment.Status)
+
+		if payment.Kind == types.PaymentKindRide && (status == types.PaymentStatusFailed || status == types.PaymentStatusExpired) {
+			return c.retryRidePayment(ctx, payment)
+		}
+
+		return nil
+	})
+}
+
+func (c *PaymentConsumer) handleRefund(ctx context.Context, payload messaging.PaymentStatusUpdateData) error {
+	kind := types.PaymentKindRide
+	if payload.PaymentType == messaging.PaymentTypeCancellationFee {
+		kind = types.PaymentKindCancellationFee
+	}
+
+	payment, err := c.service.RefundPayment(ctx, payload.TripID, kind)
+	if err != nil {
+		if errors.Is(err, domain.ErrPaymentNotFound) || errors.Is(err, domain.ErrInvalidPaymentTransition) {
+			log.Printf("Ignoring refund of trip %s: %v", payload.TripID, err)
+			return nil
+		}
+		return err
+	}
+
+	log.Printf("Payment %s of trip %s has been refunded", payment.ID, payment.TripID)
+	return nil
+}
+
+// retryRidePayment gives the rider a new checkout session after a failed or expired one.
+// On redelivery the session opened the first time is announced again.
+func (c *PaymentConsumer) retryRidePayment(ctx context.Context, payment *types.Payment) error {
+	paymentSession, err := c.service.RetryRidePayment(ctx, payment.TripID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPaymentNotRetryable) {
+			log.Printf("Not retrying the payment of trip %s: %v", payment.TripID, err)
+			return nil
+		}
+		return err
+	}
+
+	log.Printf("Payment session %s created to retry the payment of trip %s", paymentSession.StripeSessionID, payment.TripID)
+
+	return publishSessionCreated(ctx, c.rabbitmq, payment.UserID, payment.TripID, paymentSession)
+}
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ride-sharing/services/payment-service/pkg/types"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+)
+
+// routing key of the payment event announcing each outcome
+var paymentEventRoutingKeys = map[types.PaymentStatus]string{
+	types.PaymentStatusSuccess:  contracts.PaymentEventSuccess,
+	types.PaymentStatusFailed:   contracts.PaymentEventFailed,
+	types.PaymentStatusExpired:  contracts.PaymentEventCancelled,
+	types.PaymentStatusRefunded: contracts.PaymentEventRefunded,
+}
+
+type PaymentEventPublisher struct {
+	rabbitmq *messaging.RabbitMQ
+}
+
+func NewPaymentEventPublisher(rabbitmq *messaging.RabbitMQ) *PaymentEventPublisher {
+	return &PaymentEventPublisher{
+		rabbitmq: rabbitmq,
+	}
+}
+
+// PublishWebhookEvent announces the outcome reported by the webhook event to the rider and
+// the other services
+func (p *PaymentEventPublisher) PublishWebhookEvent(ctx context.Context, event *types.WebhookEvent) error {
+	routingKey, ok := paymentEventRoutingKeys[event.Status]
+	if !ok {
+		return fmt.Errorf("no payment event for status %q", event.Status)
+	}
+
+	payload := messaging.PaymentStatusUpdateData{
+		TripID:    event.TripID,
+		UserID:    event.UserID,
+		DriverID:  event.DriverID,
+		SessionID: event.SessionID,
+		Reason:    event.Reason,
+	}
+	if event.Kind == types.PaymentKindCancellationFee {
+		payload.PaymentType = messaging.PaymentTypeCancellationFee
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment event: %w", err)
+	}
+
+	// the provider event ID doubles as the message ID, so consumers see redeliveries as such
+	return p.rabbitmq.PublishMessage(ctx, routingKey, &contracts.AmqpMessage{
+		MessageID: event.ID,
+		OwnerID:   event.UserID,
+		Data:      payloadBytes,
+	})
+}
@@ -6,8 +6,10 @@ import (
 	"log"
 
 	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
 	"ride-sharing/shared/contracts"
 	"ride-sharing/shared/messaging"
+	"ride-sharing/shared/util"
 
 	"github.com/rabbitmq/amqp091-go"
 )
@@ -25,6 +27,10 @@ func NewTripConsumer(rabbitmq *messaging.RabbitMQ, service domain.Service) *Trip
 }
 
 func (c *TripConsumer) Listen() error {
+	if err := c.rabbitmq.ConsumeMessages(messaging.PaymentTripCancelledQueue, c.handleTripCancelled); err != nil {
+		return err
+	}
+
 	return c.rabbitmq.ConsumeMessages(messaging.PaymentTripResponseQueue, func(ctx context.Context, msg amqp091.Delivery) error {
 		var message contracts.AmqpMessage
 		if err := json.Unmarshal(msg.Body, &message); err != nil {
@@ -50,6 +56,53 @@ func (c *TripConsumer) Listen() error {
 	})
 }
 
+func (c *TripConsumer) handleTripCancelled(ctx context.Context, msg amqp091.Delivery) error {
+	var message contracts.AmqpMessage
+	if err := json.Unmarshal(msg.Body, &message); err != nil {
+		log.Printf("Failed to unmarshal message: %v", err)
+		return err
+	}
+
+	var payload messaging.TripCancelledData
+	if err := json.Unmarshal(message.Data, &payload); err != nil {
+		log.Printf("Failed to unmarshal payload: %v", err)
+		return err
+	}
+
+	tripID := payload.Trip.GetId()
+	userID := payload.Trip.GetUserID()
+
+	// the ride will not happen, its checkout session must not be paid anymore
+	if err := c.service.CancelPendingRidePayments(ctx, tripID); err != nil {
+		log.Printf("Failed to cancel the payments of trip %s: %v", tripID, err)
+		return err
+	}
+
+	if payload.CancellationFeeInCents <= 0 {
+		return nil
+	}
+
+	// the fee is in the currency the rider was quoted
+	currency := payload.Trip.GetSelectedFare().GetCurrency()
+
+	log.Printf("Charging cancellation fee of %d %s minor units for trip: %s", payload.CancellationFeeInCents, currency, tripID)
+
+	paymentSession, err := c.service.ChargeCancellationFee(
+		ctx,
+		tripID,
+		userID,
+		payload.Trip.GetDriver().GetId(),
+		payload.CancellationFeeInCents,
+		currency,
+	)
+	if err != nil {
+		log.Printf("Failed to create cancellation fee session: %v", err)
+		return err
+	}
+
+	return publishSessionCreated(ctx, c.rabbitmq, userID, tripID, paymentSession)
+}
+
 func (c *TripConsumer) handleTripAccepted(ctx context.Context, payload messaging.PaymentTripResponseData) error {
 	log.Printf("Handling trip accepted by driver: %s", payload.TripID)
 
@@ -58,7 +111,8 @@ func (c *TripConsumer) handleTripAccepted(ctx context.Context, payload messaging
 		payload.TripID,
 		payload.UserID,
 		payload.DriverID,
-		int64(payload.Amount),
+		payload.Amount,
+		payload.Currency,
 	)
 	if err != nil {
 		log.Printf("Failed to create payment session: %v", err)
@@ -67,12 +121,17 @@ func (c *TripConsumer) handleTripAccepted(ctx context.Context, payload messaging
 
 	log.Printf("Payment session created: %s", paymentSession.StripeSessionID)
 
-	// Publish payment session created event
+	return publishSessionCreated(ctx, c.rabbitmq, payload.UserID, payload.TripID, paymentSession)
+}
+
+// publishSessionCreated notifies the rider that a checkout session is ready to be paid
+func publishSessionCreated(ctx context.Context, rabbitmq *messaging.RabbitMQ, userID, tripID string, paymentSession *types.PaymentIntent) error {
 	paymentPayload := messaging.PaymentEventSessionCreatedData{
-		TripID:    payload.TripID,
-		SessionID: paymentSession.StripeSessionID,
-		Amount:    float64(paymentSession.Amount) / 100.0, // Convert from cents to dollars
-		Currency:  paymentSession.Currency,
+		TripID:      tripID,
+		SessionID:   paymentSession.StripeSessionID,
+		CheckoutURL: paymentSession.CheckoutURL,
+		Amount:      util.ToMajorUnits(paymentSession.Amount, paymentSession.Currency),
+		Currency:    paymentSession.Currency,
 	}
 
 	payloadBytes, err := json.Marshal(paymentPayload)
@@ -81,9 +140,9 @@ func (c *TripConsumer) handleTripAccepted(ctx context.Context, payload messaging
 		return err
 	}
 
-	if err := c.rabbitmq.PublishMessage(ctx, contracts.PaymentEventSessionCreated,
+	if err := rabbitmq.PublishMessage(ctx, contracts.PaymentEventSessionCreated,
 		&contracts.AmqpMessage{
-			OwnerID: payload.UserID,
+			OwnerID: userID,
 			Data:    payloadBytes,
 		},
 	); err != nil {
@@ -91,6 +150,6 @@ func (c *TripConsumer) handleTripAccepted(ctx context.Context, payload messaging
 		return err
 	}
 
-	log.Printf("Published payment session created event for trip: %s", payload.TripID)
+	log.Printf("Published payment session created event for trip: %s", tripID)
 	return nil
 }
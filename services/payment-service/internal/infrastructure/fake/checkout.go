@@ -0,0 +1,115 @@
+package fake
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
+)
+
+// checkoutAction is what the rider can do with a session on the checkout page
+type checkoutAction struct {
+	from, to sessionStatus
+	status   types.PaymentStatus // reported by the webhook
+	reason   string
+}
+
+var checkoutActions = map[string]checkoutAction{
+	"pay":    {from: sessionOpen, to: sessionPaid, status: types.PaymentStatusSuccess},
+	"fail":   {from: sessionOpen, to: sessionFailed, status: types.PaymentStatusFailed, reason: "the payment was declined"},
+	"expire": {from: sessionOpen, to: sessionExpired, status: types.PaymentStatusExpired, reason: "the checkout session expired"},
+	"refund": {from: sessionPaid, to: sessionRefunded, status: types.PaymentStatusRefunded, reason: "the payment was refunded"},
+}
+
+var checkoutPage = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Fake checkout</title></head>
+<body style="font-family: sans-serif; max-width: 420px; margin: 40px auto">
+  <h1>Fake checkout</h1>
+  <p>Session <code>{{.ID}}</code> is <strong>{{.Status}}</strong>.</p>
+  <p>Trip {{index .Metadata "trip_id"}}: {{.Price}}</p>
+  {{if eq .Status "open"}}
+  <form method="post" action="/checkout/{{.ID}}/pay"><button>Pay</button></form>
+  <form method="post" action="/checkout/{{.ID}}/fail"><button>Decline the payment</button></form>
+  <form method="post" action="/checkout/{{.ID}}/expire"><button>Let the session expire</button></form>
+  {{else if eq .Status "paid"}}
+  <form method="post" action="/checkout/{{.ID}}/refund"><button>Refund</button></form>
+  {{end}}
+</body>
+</html>
+`))
+
+type checkoutHandler struct {
+	processor *FakeProcessor
+	service   domain.Service
+}
+
+// NewCheckoutHandler serves the checkout page of the sessions opened by the processor.
+// The outcome chosen there goes to the service as a signed webhook.
+func NewCheckoutHandler(processor *FakeProcessor, service domain.Service) http.Handler {
+	h := &checkoutHandler{
+		processor: processor,
+		service:   service,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /checkout/{id}", h.handlePage)
+	mux.HandleFunc("POST /checkout/{id}/{action}", h.handleAction)
+	return mux
+}
+
+func (h *checkoutHandler) handlePage(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.processor.getSession(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if err := checkoutPage.Execute(w, s); err != nil {
+		log.Printf("Failed to render checkout page: %v", err)
+	}
+}
+
+func (h *checkoutHandler) handleAction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	action, ok := checkoutActions[r.PathValue("action")]
+	if !ok {
+		http.Error(w, "Unknown action", http.StatusNotFound)
+		return
+	}
+
+	s, ok := h.processor.getSession(id)
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if !h.processor.settle(id, action.from, action.to) {
+		http.Error(w, "Session is not "+string(action.from), http.StatusConflict)
+		return
+	}
+
+	payload, signature, err := h.processor.webhook(s, action.status, action.reason)
+	if err == nil {
+		_, _, err = h.service.HandleWebhook(r.Context(), payload, signature)
+	}
+	if err != nil {
+		// the outcome is not known to anyone yet, the rider can try again
+		h.processor.settle(id, action.to, action.from)
+		log.Printf("Failed to report the outcome of session %s: %v", id, err)
+		http.Error(w, "Failed to report the payment outcome", http.StatusInternalServerError)
+		return
+	}
+
+	switch action.to {
+	case sessionPaid:
+		http.Redirect(w, r, h.processor.config.SuccessURL, http.StatusSeeOther)
+	case sessionFailed, sessionExpired:
+		http.Redirect(w, r, h.processor.config.CancelURL, http.StatusSeeOther)
+	default:
+		http.Redirect(w, r, "/checkout/"+id, http.StatusSeeOther)
+	}
+}
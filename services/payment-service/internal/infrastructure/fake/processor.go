@@ -0,0 +1,196 @@
+/*
+Package fake is a payment processor for running the payment flow offline. Its checkout
+sessions are paid, declined, left to expire or refunded on a local checkout page, which
+reports the outcome through the same webhook pipeline as Stripe.
+*/
+package fake
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
+	"ride-sharing/shared/util"
+)
+
+type sessionStatus string
+
+const (
+	sessionOpen     sessionStatus = "open"
+	sessionPaid     sessionStatus = "paid"
+	sessionFailed   sessionStatus = "failed"
+	sessionExpired  sessionStatus = "expired"
+	sessionRefunded sessionStatus = "refunded"
+)
+
+type session struct {
+	ID       string
+	Amount   int64 // in minor units of Currency
+	Currency string
+	Metadata map[string]string
+	Status   sessionStatus
+}
+
+// FakeProcessor keeps its sessions in memory, they are lost on restart.
+type FakeProcessor struct {
+	config *types.PaymentConfig
+	// checkoutURL is the public address of the checkout page, see NewCheckoutHandler
+	checkoutURL string
+	// webhooks are signed with a secret of the process, only this processor verifies them
+	secret []byte
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	// number of sessions opened per trip, which makes session IDs deterministic
+	opened map[string]int
+}
+
+func NewFakeProcessor(config *types.PaymentConfig, checkoutURL string) *FakeProcessor {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("failed to generate the fake webhook secret: %v", err))
+	}
+
+	return &FakeProcessor{
+		config:      config,
+		checkoutURL: strings.TrimSuffix(checkoutURL, "/"),
+		secret:      secret,
+		sessions:    make(map[string]*session),
+		opened:      make(map[string]int),
+	}
+}
+
+// CreatePaymentSession opens a session named after the trip and the number of sessions
+// the trip had before, e.g. cs_fake_<tripID>_1 for its first one.
+func (p *FakeProcessor) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string) (*types.CheckoutSession, error) {
+	tripID := metadata["trip_id"]
+	if tripID == "" {
+		return nil, fmt.Errorf("trip_id metadata is required")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.opened[tripID]++
+	id := fmt.Sprintf("cs_fake_%s_%d", tripID, p.opened[tripID])
+
+	p.sessions[id] = &session{
+		ID:       id,
+		Amount:   amount,
+		Currency: currency,
+		Metadata: metadata,
+		Status:   sessionOpen,
+	}
+
+	return &types.CheckoutSession{
+		ID:  id,
+		URL: p.checkoutURL + "/checkout/" + id,
+	}, nil
+}
+
+// ParseWebhookEvent verifies webhooks signed by sign, whose payload is the event itself.
+func (p *FakeProcessor) ParseWebhookEvent(payload []byte, signature string) (*types.WebhookEvent, error) {
+	if !hmac.Equal([]byte(signature), []byte(p.sign(payload))) {
+		return nil, fmt.Errorf("%w: bad signature", domain.ErrInvalidWebhook)
+	}
+
+	var event types.WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidWebhook, err)
+	}
+
+	return &event, nil
+}
+
+// ExpirePaymentSession closes the session like letting it expire on the checkout page,
+// without a webhook: the service already knows why the session is closed.
+func (p *FakeProcessor) ExpirePaymentSession(ctx context.Context, sessionID string) error {
+	if p.settle(sessionID, sessionOpen, sessionExpired) {
+		return nil
+	}
+
+	s, ok := p.getSession(sessionID)
+	switch {
+	case !ok:
+		return fmt.Errorf("session %s not found", sessionID)
+	case s.Status == sessionExpired:
+		return nil
+	default:
+		return fmt.Errorf("%w: session %s is %s", domain.ErrSessionNotOpen, sessionID, s.Status)
+	}
+}
+
+func (p *FakeProcessor) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Price is the amount of the session in major units, as shown on the checkout page.
+func (s session) Price() string {
+	return fmt.Sprintf("%.*f %s", util.CurrencyDecimals(s.Currency), util.ToMajorUnits(s.Amount, s.Currency), strings.ToUpper(s.Currency))
+}
+
+// getSession returns a copy of the session.
+func (p *FakeProcessor) getSession(id string) (session, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.sessions[id]
+	if !ok {
+		return session{}, false
+	}
+	return *s, true
+}
+
+// settle moves the session from one status to another, reporting whether it was in from.
+func (p *FakeProcessor) settle(id string, from, to sessionStatus) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.sessions[id]
+	if !ok || s.Status != from {
+		return false
+	}
+
+	s.Status = to
+	return true
+}
+
+// webhook returns the signed webhook that the payment provider would send for the outcome.
+// The event ID only depends on the session and the outcome, like a redelivered Stripe event.
+func (p *FakeProcessor) webhook(s session, status types.PaymentStatus, reason string) ([]byte, string, error) {
+	event := types.WebhookEvent{
+		ID:       fmt.Sprintf("evt_fake_%s_%s", s.ID, status),
+		Status:   status,
+		TripID:   s.Metadata["trip_id"],
+		UserID:   s.Metadata["user_id"],
+		DriverID: s.Metadata["driver_id"],
+		Kind:     types.PaymentKindRide,
+		Reason:   reason,
+	}
+
+	if s.Metadata["payment_type"] == string(types.PaymentKindCancellationFee) {
+		event.Kind = types.PaymentKindCancellationFee
+	}
+
+	// refunds are reported per trip, like Stripe charges
+	if status != types.PaymentStatusRefunded {
+		event.SessionID = s.ID
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, p.sign(payload), nil
+}
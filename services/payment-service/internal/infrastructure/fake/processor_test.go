@@ -0,0 +1,53 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
+)
+
+func TestExpirePaymentSession(t *testing.T) {
+	tests := []struct {
+		status  sessionStatus
+		wantErr error
+	}{
+		{sessionOpen, nil},
+		{sessionExpired, nil},
+		{sessionPaid, domain.ErrSessionNotOpen},
+		{sessionFailed, domain.ErrSessionNotOpen},
+		{sessionRefunded, domain.ErrSessionNotOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			ctx := context.Background()
+			p := NewFakeProcessor(&types.PaymentConfig{}, "http://localhost:9005")
+
+			session, err := p.CreatePaymentSession(ctx, 1000, "usd", map[string]string{"trip_id": "trip-1"})
+			if err != nil {
+				t.Fatalf("CreatePaymentSession() = %v", err)
+			}
+			p.sessions[session.ID].Status = tt.status
+
+			if err := p.ExpirePaymentSession(ctx, session.ID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExpirePaymentSession() = %v, want %v", err, tt.wantErr)
+			}
+
+			s, _ := p.getSession(session.ID)
+			if tt.wantErr == nil && s.Status != sessionExpired {
+				t.Errorf("session status = %q, want %q", s.Status, sessionExpired)
+			}
+		})
+	}
+}
+
+func TestExpireUnknownSession(t *testing.T) {
+	p := NewFakeProcessor(&types.PaymentConfig{}, "http://localhost:9005")
+
+	if err := p.ExpirePaymentSession(context.Background(), "cs_fake_unknown_1"); err == nil {
+		t.Error("ExpirePaymentSession() of an unknown session = nil, want an error")
+	}
+}
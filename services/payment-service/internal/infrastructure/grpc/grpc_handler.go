@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
+	"ride-sharing/shared/auth"
+	pb "ride-sharing/shared/proto/payment"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type gRPCHandler struct {
+	pb.UnimplementedPaymentServiceServer
+	service domain.Service
+}
+
+func NewGRPCHandler(server *grpc.Server, service domain.Service) *gRPCHandler {
+	handler := &gRPCHandler{
+		service: service,
+	}
+
+	pb.RegisterPaymentServiceServer(server, handler)
+	return handler
+}
+
+func (h *gRPCHandler) GetPaymentByTrip(ctx context.Context, req *pb.GetPaymentByTripRequest) (*pb.GetPaymentResponse, error) {
+	if req.GetTripID() == "" {
+		return nil, status.Error(codes.InvalidArgument, "trip ID is required")
+	}
+
+	userID, err := auth.IncomingUserID(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := h.service.GetPaymentByTrip(ctx, req.GetTripID())
+	if err != nil {
+		return nil, paymentError(err)
+	}
+	if !paidBy(payment, userID) {
+		return nil, status.Errorf(codes.NotFound, "no payment for trip %s", req.GetTripID())
+	}
+
+	return &pb.GetPaymentResponse{Payment: toProto(payment)}, nil
+}
+
+func (h *gRPCHandler) ListPaymentsByUser(ctx context.Context, req *pb.ListPaymentsByUserRequest) (*pb.ListPaymentsResponse, error) {
+	userID, err := auth.IncomingUserID(ctx, req.GetUserID())
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.service.ListPaymentsByUser(ctx, userID, int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		return nil, paymentError(err)
+	}
+
+	payments := make([]*pb.Payment, len(page.Payments))
+	for i, payment := range page.Payments {
+		payments[i] = toProto(payment)
+	}
+
+	return &pb.ListPaymentsResponse{
+		Payments:      payments,
+		NextPageToken: page.NextPageToken,
+	}, nil
+}
+
+func (h *gRPCHandler) HandleStripeWebhook(ctx context.Context, req *pb.HandleStripeWebhookRequest) (*pb.HandleStripeWebhookResponse, error) {
+	if len(req.GetPayload()) == 0 || req.GetSignature() == "" {
+		return nil, status.Error(codes.InvalidArgument, "payload and signature are required")
+	}
+
+	eventID, duplicate, err := h.service.HandleWebhook(ctx, req.GetPayload(), req.GetSignature())
+	if err != nil {
+		return nil, paymentError(err)
+	}
+
+	return &pb.HandleStripeWebhookResponse{
+		EventID:   eventID,
+		Duplicate: duplicate,
+	}, nil
+}
+
+func (h *gRPCHandler) GetPaymentStatus(ctx context.Context, req *pb.GetPaymentStatusRequest) (*pb.GetPaymentStatusResponse, error) {
+	if req.GetPaymentID() == "" {
+		return nil, status.Error(codes.InvalidArgument, "payment ID is required")
+	}
+
+	userID, err := auth.IncomingUserID(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := h.service.GetPayment(ctx, req.GetPaymentID())
+	if err != nil {
+		return nil, paymentError(err)
+	}
+	if !paidBy(payment, userID) {
+		return nil, status.Errorf(codes.NotFound, "payment %s not found", req.GetPaymentID())
+	}
+
+	return &pb.GetPaymentStatusResponse{
+		PaymentID: payment.ID,
+		Status:    string(payment.Status),
+	}, nil
+}
+
+// paidBy tells whether the user is the rider or the driver of the payment. Other users are
+// told it does not exist.
+func paidBy(payment *types.Payment, userID string) bool {
+	return payment.UserID == userID || payment.DriverID == userID
+}
+
+// paymentError maps service errors to gRPC status codes
+func paymentError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrPaymentNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidPageToken), errors.Is(err, domain.ErrInvalidWebhook):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func toProto(p *types.Payment) *pb.Payment {
+	return &pb.Payment{
+		Id:        p.ID,
+		TripID:    p.TripID,
+		UserID:    p.UserID,
+		DriverID:  p.DriverID,
+		Amount:    p.Amount,
+		Currency:  p.Currency,
+		Status:    string(p.Status),
+		Kind:      string(p.Kind),
+		SessionID: p.StripeSessionID,
+		CreatedAt: p.CreatedAt.Unix(),
+		UpdatedAt: p.UpdatedAt.Unix(),
+	}
+}
@@ -0,0 +1,149 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
+	"ride-sharing/shared/auth"
+	pb "ride-sharing/shared/proto/payment"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// paymentLookup answers the lookups of a single payment and the listing of its user.
+type paymentLookup struct {
+	domain.Service
+	payment *types.Payment
+	listed  []string // users whose payments were listed
+}
+
+func (s *paymentLookup) GetPaymentByTrip(ctx context.Context, tripID string) (*types.Payment, error) {
+	if tripID != s.payment.TripID {
+		return nil, domain.ErrPaymentNotFound
+	}
+	return s.payment, nil
+}
+
+func (s *paymentLookup) GetPayment(ctx context.Context, id string) (*types.Payment, error) {
+	if id != s.payment.ID {
+		return nil, domain.ErrPaymentNotFound
+	}
+	return s.payment, nil
+}
+
+func (s *paymentLookup) ListPaymentsByUser(ctx context.Context, userID string, pageSize int, pageToken string) (*types.PaymentPage, error) {
+	s.listed = append(s.listed, userID)
+	return &types.PaymentPage{}, nil
+}
+
+func asUser(userID string, role auth.Role) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		auth.UserIDMetadataKey, userID,
+		auth.RoleMetadataKey, string(role),
+	))
+}
+
+func newPaymentLookup() *paymentLookup {
+	return &paymentLookup{payment: &types.Payment{
+		ID:       "payment-1",
+		TripID:   "trip-1",
+		UserID:   "rider-1",
+		DriverID: "driver-1",
+		Status:   types.PaymentStatusPending,
+	}}
+}
+
+func TestGetPaymentByTrip(t *testing.T) {
+	handler := &gRPCHandler{service: newPaymentLookup()}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		tripID string
+		want   codes.Code
+	}{
+		{"rider", asUser("rider-1", auth.RoleRider), "trip-1", codes.OK},
+		{"driver", asUser("driver-1", auth.RoleDriver), "trip-1", codes.OK},
+		{"another rider", asUser("rider-2", auth.RoleRider), "trip-1", codes.NotFound},
+		{"unknown trip", asUser("rider-1", auth.RoleRider), "trip-2", codes.NotFound},
+		{"no trip ID", asUser("rider-1", auth.RoleRider), "", codes.InvalidArgument},
+		{"no authenticated user", context.Background(), "trip-1", codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := handler.GetPaymentByTrip(tt.ctx, &pb.GetPaymentByTripRequest{TripID: tt.tripID})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("GetPaymentByTrip() = %v, want %s", err, tt.want)
+			}
+			if tt.want == codes.OK && resp.GetPayment().GetId() != "payment-1" {
+				t.Errorf("GetPaymentByTrip() = %v, want payment-1", resp.GetPayment())
+			}
+		})
+	}
+}
+
+func TestGetPaymentStatus(t *testing.T) {
+	handler := &gRPCHandler{service: newPaymentLookup()}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		paymentID string
+		want      codes.Code
+	}{
+		{"rider", asUser("rider-1", auth.RoleRider), "payment-1", codes.OK},
+		{"another rider", asUser("rider-2", auth.RoleRider), "payment-1", codes.NotFound},
+		{"no authenticated user", context.Background(), "payment-1", codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.GetPaymentStatus(tt.ctx, &pb.GetPaymentStatusRequest{PaymentID: tt.paymentID})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("GetPaymentStatus() = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestListPaymentsByUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		userID     string
+		want       codes.Code
+		wantListed string
+	}{
+		{"caller", asUser("rider-1", auth.RoleRider), "", codes.OK, "rider-1"},
+		{"caller named in the request", asUser("rider-1", auth.RoleRider), "rider-1", codes.OK, "rider-1"},
+		{"another user", asUser("rider-1", auth.RoleRider), "rider-2", codes.PermissionDenied, ""},
+		{"no authenticated user", context.Background(), "rider-1", codes.Unauthenticated, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newPaymentLookup()
+			handler := &gRPCHandler{service: service}
+
+			_, err := handler.ListPaymentsByUser(tt.ctx, &pb.ListPaymentsByUserRequest{UserID: tt.userID})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("ListPaymentsByUser() = %v, want %s", err, tt.want)
+			}
+
+			if tt.wantListed == "" {
+				if len(service.listed) != 0 {
+					t.Errorf("listed the payments of %v, want none", service.listed)
+				}
+				return
+			}
+			if len(service.listed) != 1 || service.listed[0] != tt.wantListed {
+				t.Errorf("listed the payments of %v, want %s", service.listed, tt.wantListed)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
+)
+
+type inmemRepository struct {
+	mu            sync.RWMutex
+	payments      map[string]*types.Payment
+	webhookEvents map[string]time.Time
+}
+
+func NewInmemRepository() *inmemRepository {
+	return &inmemRepository{
+		payments:      make(map[string]*types.Payment),
+		webhookEvents: make(map[string]time.Time),
+	}
+}
+
+func (r *inmemRepository) SavePayment(ctx context.Context, payment *types.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *payment
+	r.payments[payment.ID] = &stored
+	return nil
+}
+
+func (r *inmemRepository) GetPayment(ctx context.Context, id string) (*types.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, domain.ErrPaymentNotFound
+	}
+
+	found := *payment
+	return &found, nil
+}
+
+func (r *inmemRepository) GetPaymentBySession(ctx context.Context, sessionID string) (*types.Payment, error) {
+	payments := r.find(func(p *types.Payment) bool { return p.StripeSessionID == sessionID })
+	if len(payments) == 0 {
+		return nil, domain.ErrPaymentNotFound
+	}
+	return payments[0], nil
+}
+
+func (r *inmemRepository) GetLatestPaymentByTrip(ctx context.Context, tripID string) (*types.Payment, error) {
+	payments := r.find(func(p *types.Payment) bool { return p.TripID == tripID })
+	if len(payments) == 0 {
+		return nil, domain.ErrPaymentNotFound
+	}
+	return payments[0], nil
+}
+
+func (r *inmemRepository) ListPaymentsByTrip(ctx context.Context, tripID string) ([]*types.Payment, error) {
+	return r.find(func(p *types.Payment) bool { return p.TripID == tripID }), nil
+}
+
+func (r *inmemRepository) ListPaymentsByUser(ctx context.Context, userID string, offset, limit int) ([]*types.Payment, error) {
+	payments := r.find(func(p *types.Payment) bool { return p.UserID == userID })
+
+	if offset >= len(payments) {
+		return nil, nil
+	}
+
+	payments = payments[offset:]
+	if len(payments) > limit {
+		payments = payments[:limit]
+	}
+
+	return payments, nil
+}
+
+func (r *inmemRepository) UpdatePaymentStatus(ctx context.Context, id string, from, to types.PaymentStatus, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[id]
+	if !ok {
+		return domain.ErrPaymentNotFound
+	}
+
+	if payment.Status != from {
+		return domain.ErrPaymentStatusChanged
+	}
+
+	payment.Status = to
+	payment.UpdatedAt = at
+	return nil
+}
+
+func (r *inmemRepository) ClaimWebhookEvent(ctx context.Context, eventID string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhookEvents[eventID]; ok {
+		return false, nil
+	}
+	r.webhookEvents[eventID] = at
+	return true, nil
+}
+
+func (r *inmemRepository) ReleaseWebhookEvent(ctx context.Context, eventID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.webhookEvents, eventID)
+	return nil
+}
+
+// find returns copies of the matching payments, newest first, ties broken by ID so that pages are stable.
+func (r *inmemRepository) find(match func(*types.Payment) bool) []*types.Payment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var payments []*types.Payment
+	for _, payment := range r.payments {
+		if match(payment) {
+			found := *payment
+			payments = append(payments, &found)
+		}
+	}
+
+	sort.Slice(payments, func(i, j int) bool {
+		if !payments[i].CreatedAt.Equal(payments[j].CreatedAt) {
+			return payments[i].CreatedAt.After(payments[j].CreatedAt)
+		}
+		return payments[i].ID > payments[j].ID
+	})
+
+	return payments
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
+	"ride-sharing/shared/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newest first, ties broken by ID so that pages are stable
+var newestFirst = bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+
+type mongoRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoRepository(db *mongo.Database) *mongoRepository {
+	return &mongoRepository{db: db}
+}
+
+func (r *mongoRepository) SavePayment(ctx context.Context, payment *types.Payment) error {
+	_, err := r.db.Collection(db.PaymentsCollection).InsertOne(ctx, payment)
+	return err
+}
+
+func (r *mongoRepository) GetPayment(ctx context.Context, id string) (*types.Payment, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoRepository) GetPaymentBySession(ctx context.Context, sessionID string) (*types.Payment, error) {
+	return r.findOne(ctx, bson.M{"stripe_session_id": sessionID})
+}
+
+func (r *mongoRepository) GetLatestPaymentByTrip(ctx context.Context, tripID string) (*types.Payment, error) {
+	return r.findOne(ctx, bson.M{"trip_id": tripID})
+}
+
+func (r *mongoRepository) ListPaymentsByTrip(ctx context.Context, tripID string) ([]*types.Payment, error) {
+	return r.find(ctx, bson.M{"trip_id": tripID}, options.Find().SetSort(newestFirst))
+}
+
+func (r *mongoRepository) ListPaymentsByUser(ctx context.Context, userID string, offset, limit int) ([]*types.Payment, error) {
+	opts := options.Find().
+		SetSort(newestFirst).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	return r.find(ctx, bson.M{"user_id": userID}, opts)
+}
+
+func (r *mongoRepository) UpdatePaymentStatus(ctx context.Context, id string, from, to types.PaymentStatus, at time.Time) error {
+	result, err := r.db.Collection(db.PaymentsCollection).UpdateOne(ctx,
+		bson.M{"_id": id, "status": from},
+		bson.M{"$set": bson.M{"status": to, "updated_at": at}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := r.GetPayment(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrPaymentStatusChanged
+	}
+
+	return nil
+}
+
+// ClaimWebhookEvent relies on the unique _id index: of concurrent inserts of the event, one succeeds.
+func (r *mongoRepository) ClaimWebhookEvent(ctx context.Context, eventID string, at time.Time) (bool, error) {
+	_, err := r.db.Collection(db.WebhookEventsCollection).InsertOne(ctx, bson.M{
+		"_id":          eventID,
+		"processed_at": at,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *mongoRepository) ReleaseWebhookEvent(ctx context.Context, eventID string) error {
+	_, err := r.db.Collection(db.WebhookEventsCollection).DeleteOne(ctx, bson.M{"_id": eventID})
+	return err
+}
+
+func (r *mongoRepository) findOne(ctx context.Context, filter bson.M) (*types.Payment, error) {
+	result := r.db.Collection(db.PaymentsCollection).FindOne(ctx, filter, options.FindOne().SetSort(newestFirst))
+	if err := result.Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	var payment types.Payment
+	if err := result.Decode(&payment); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *mongoRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*types.Payment, error) {
+	cursor, err := r.db.Collection(db.PaymentsCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var payments []*types.Payment
+	if err := cursor.All(ctx, &payments); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
@@ -23,15 +23,21 @@ func NewStripeClient(config *types.PaymentConfig) domain.PaymentProcessor {
 	}
 }
 
-func (s *stripeClient) CreatePaymentSession(ctx context.Context, amount int64, metadata map[string]string) (string, error) {
+// CreatePaymentSession charges amount in minor units of the currency, which is what Stripe
+// expects for zero-decimal currencies too.
+func (s *stripeClient) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string) (*types.CheckoutSession, error) {
 	params := &stripe.CheckoutSessionParams{
 		SuccessURL: stripe.String(s.config.SuccessURL),
 		CancelURL:  stripe.String(s.config.CancelURL),
 		Metadata:   metadata,
+		// copied to the charge, so that refunds can be traced back to the trip
+		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+			Metadata: metadata,
+		},
 		LineItems: []*stripe.CheckoutSessionLineItemParams{
 			{
 				PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-					Currency: stripe.String("usd"), // Assuming USD, can be parameterized
+					Currency: stripe.String(currency),
 					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
 						Name: stripe.String("Ride Payment"),
 					},
@@ -45,8 +51,30 @@ func (s *stripeClient) CreatePaymentSession(ctx context.Context, amount int64, m
 
 	result, err := session.New(params)
 	if err != nil {
-		return "", fmt.Errorf("failed to create a payment session on stripe: %w", err)
+		return nil, fmt.Errorf("failed to create a payment session on stripe: %w", err)
+	}
+
+	return &types.CheckoutSession{ID: result.ID, URL: result.URL}, nil
+}
+
+func (s *stripeClient) ExpirePaymentSession(ctx context.Context, sessionID string) error {
+	_, expireErr := session.Expire(sessionID, nil)
+	if expireErr == nil {
+		return nil
+	}
+
+	// Stripe only expires open sessions, find out whether this one was still open
+	current, err := session.Get(sessionID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to expire payment session %s on stripe: %w", sessionID, expireErr)
+	}
+
+	switch current.Status {
+	case stripe.CheckoutSessionStatusExpired:
+		return nil
+	case stripe.CheckoutSessionStatusComplete:
+		return fmt.Errorf("%w: session %s is complete", domain.ErrSessionNotOpen, sessionID)
 	}
 
-	return result.ID, nil
+	return fmt.Errorf("failed to expire payment session %s on stripe: %w", sessionID, expireErr)
 }
@@ -0,0 +1,97 @@
+package stripe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/pkg/types"
+
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/webhook"
+)
+
+func (s *stripeClient) ParseWebhookEvent(payload []byte, signature string) (*types.WebhookEvent, error) {
+	if s.config.StripeWebhookSecret == "" {
+		return nil, errors.New("stripe webhook secret is not set")
+	}
+
+	event, err := webhook.ConstructEventWithOptions(payload, signature, s.config.StripeWebhookSecret, webhook.ConstructEventOptions{
+		IgnoreAPIVersionMismatch: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidWebhook, err)
+	}
+
+	result, err := webhookEvent(event)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse %s event: %v", domain.ErrInvalidWebhook, event.Type, err)
+	}
+
+	return result, nil
+}
+
+// webhookEvent maps a Stripe event to the outcome of the payment it is about.
+func webhookEvent(event stripe.Event) (*types.WebhookEvent, error) {
+	result := &types.WebhookEvent{ID: event.ID}
+
+	switch event.Type {
+	case stripe.EventTypeCheckoutSessionCompleted,
+		stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded,
+		stripe.EventTypeCheckoutSessionAsyncPaymentFailed,
+		stripe.EventTypeCheckoutSessionExpired:
+		var session stripe.CheckoutSession
+		if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
+			return nil, err
+		}
+
+		readMetadata(result, session.Metadata)
+		result.SessionID = session.ID
+
+		switch event.Type {
+		case stripe.EventTypeCheckoutSessionCompleted:
+			// delayed payment methods complete the session before the money arrives,
+			// async_payment_succeeded or async_payment_failed follow
+			if session.PaymentStatus != stripe.CheckoutSessionPaymentStatusUnpaid {
+				result.Status = types.PaymentStatusSuccess
+			}
+		case stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded:
+			result.Status = types.PaymentStatusSuccess
+		case stripe.EventTypeCheckoutSessionAsyncPaymentFailed:
+			result.Status = types.PaymentStatusFailed
+			result.Reason = "the payment was declined"
+		default:
+			result.Status = types.PaymentStatusExpired
+			result.Reason = "the checkout session expired"
+		}
+
+	case stripe.EventTypeChargeRefunded:
+		var charge stripe.Charge
+		if err := json.Unmarshal(event.Data.Raw, &charge); err != nil {
+			return nil, err
+		}
+
+		// the event is sent for partial refunds too, only a full refund undoes the payment
+		if charge.Refunded {
+			readMetadata(result, charge.Metadata)
+			result.Status = types.PaymentStatusRefunded
+			result.Reason = "the payment was refunded"
+		}
+	}
+
+	return result, nil
+}
+
+// readMetadata reads the metadata attached to checkout sessions and their charges, see
+// CreatePaymentSession.
+func readMetadata(event *types.WebhookEvent, metadata map[string]string) {
+	event.TripID = metadata["trip_id"]
+	event.UserID = metadata["user_id"]
+	event.DriverID = metadata["driver_id"]
+
+	event.Kind = types.PaymentKindRide
+	if metadata["payment_type"] == string(types.PaymentKindCancellationFee) {
+		event.Kind = types.PaymentKindCancellationFee
+	}
+}
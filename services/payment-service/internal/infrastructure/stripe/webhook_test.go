@@ -0,0 +1,120 @@
+package stripe
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ride-sharing/services/payment-service/pkg/types"
+
+	"github.com/stripe/stripe-go/v81"
+)
+
+func TestWebhookEvent(t *testing.T) {
+	rideMetadata := map[string]string{"trip_id": "trip-1", "user_id": "rider-1", "driver_id": "driver-1"}
+	feeMetadata := map[string]string{"trip_id": "trip-1", "user_id": "rider-1", "driver_id": "driver-1", "payment_type": "cancellation_fee"}
+
+	session := func(paymentStatus stripe.CheckoutSessionPaymentStatus, metadata map[string]string) any {
+		return map[string]any{"id": "cs_1", "object": "checkout.session", "payment_status": paymentStatus, "metadata": metadata}
+	}
+	charge := func(refunded bool) any {
+		return map[string]any{"id": "ch_1", "object": "charge", "refunded": refunded, "metadata": rideMetadata}
+	}
+	// the event of the trip, as read from the metadata
+	event := func(status types.PaymentStatus, sessionID string, kind types.PaymentKind, reason string) types.WebhookEvent {
+		return types.WebhookEvent{
+			ID:        "evt_1",
+			Status:    status,
+			SessionID: sessionID,
+			TripID:    "trip-1",
+			UserID:    "rider-1",
+			DriverID:  "driver-1",
+			Kind:      kind,
+			Reason:    reason,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		eventType stripe.EventType
+		object    any
+		want      types.WebhookEvent
+	}{
+		{
+			name:      "paid checkout",
+			eventType: stripe.EventTypeCheckoutSessionCompleted,
+			object:    session(stripe.CheckoutSessionPaymentStatusPaid, rideMetadata),
+			want:      event(types.PaymentStatusSuccess, "cs_1", types.PaymentKindRide, ""),
+		},
+		{
+			name:      "checkout waiting for a delayed payment",
+			eventType: stripe.EventTypeCheckoutSessionCompleted,
+			object:    session(stripe.CheckoutSessionPaymentStatusUnpaid, rideMetadata),
+			want:      event("", "cs_1", types.PaymentKindRide, ""),
+		},
+		{
+			name:      "delayed payment succeeded",
+			eventType: stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded,
+			object:    session(stripe.CheckoutSessionPaymentStatusPaid, rideMetadata),
+			want:      event(types.PaymentStatusSuccess, "cs_1", types.PaymentKindRide, ""),
+		},
+		{
+			name:      "delayed payment failed",
+			eventType: stripe.EventTypeCheckoutSessionAsyncPaymentFailed,
+			object:    session(stripe.CheckoutSessionPaymentStatusUnpaid, rideMetadata),
+			want:      event(types.PaymentStatusFailed, "cs_1", types.PaymentKindRide, "the payment was declined"),
+		},
+		{
+			name:      "expired checkout of a cancellation fee",
+			eventType: stripe.EventTypeCheckoutSessionExpired,
+			object:    session(stripe.CheckoutSessionPaymentStatusUnpaid, feeMetadata),
+			want:      event(types.PaymentStatusExpired, "cs_1", types.PaymentKindCancellationFee, "the checkout session expired"),
+		},
+		{
+			name:      "full refund",
+			eventType: stripe.EventTypeChargeRefunded,
+			object:    charge(true),
+			want:      event(types.PaymentStatusRefunded, "", types.PaymentKindRide, "the payment was refunded"),
+		},
+		{
+			name:      "partial refund",
+			eventType: stripe.EventTypeChargeRefunded,
+			object:    charge(false),
+			want:      types.WebhookEvent{ID: "evt_1"},
+		},
+		{
+			name:      "unrelated event",
+			eventType: stripe.EventTypeCustomerCreated,
+			object:    map[string]any{"id": "cus_1", "object": "customer"},
+			want:      types.WebhookEvent{ID: "evt_1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := json.Marshal(tt.object)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := webhookEvent(stripe.Event{ID: "evt_1", Type: tt.eventType, Data: &stripe.EventData{Raw: raw}})
+			if err != nil {
+				t.Fatalf("webhookEvent() = %v", err)
+			}
+
+			if *got != tt.want {
+				t.Errorf("webhookEvent() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookEventMalformed(t *testing.T) {
+	_, err := webhookEvent(stripe.Event{
+		ID:   "evt_1",
+		Type: stripe.EventTypeCheckoutSessionCompleted,
+		Data: &stripe.EventData{Raw: json.RawMessage(`{"id": 42}`)},
+	})
+	if err == nil {
+		t.Error("webhookEvent() of a malformed session = nil, want an error")
+	}
+}
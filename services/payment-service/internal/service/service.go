@@ -2,23 +2,42 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"ride-sharing/services/payment-service/internal/domain"
 	"ride-sharing/services/payment-service/pkg/types"
+	"ride-sharing/shared/messaging"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// the currency of the sessions asked for without one
+	defaultCurrency = "usd"
+
+	// a rider gets a new checkout session after a failed or expired one, up to this many in total
+	maxRidePaymentAttempts = 3
+
+	defaultPaymentsPageSize = 20
+	maxPaymentsPageSize     = 100
+)
+
 type paymentService struct {
 	paymentProcessor domain.PaymentProcessor
+	repo             domain.PaymentRepository
+	publisher        domain.PaymentEventPublisher
 }
 
 // NewPaymentService creates a new instance of the payment service
-func NewPaymentService(p domain.PaymentProcessor) domain.Service {
+func NewPaymentService(p domain.PaymentProcessor, repo domain.PaymentRepository, publisher domain.PaymentEventPublisher) domain.Service {
 	return &paymentService{
 		paymentProcessor: p,
+		repo:             repo,
+		publisher:        publisher,
 	}
 }
 
@@ -29,6 +48,7 @@ func (s *paymentService) CreatePaymentSession(
 	userID string,
 	driverID string,
 	amount int64,
+	currency string,
 ) (*types.PaymentIntent, error) {
 	metadata := map[string]string{
 		"trip_id":   tripID,
@@ -36,20 +56,318 @@ func (s *paymentService) CreatePaymentSession(
 		"driver_id": driverID,
 	}
 
-	sessionID, err := s.paymentProcessor.CreatePaymentSession(ctx, amount, metadata)
+	return s.createPaymentIntent(ctx, tripID, userID, driverID, amount, currency, types.PaymentKindRide, metadata)
+}
+
+// ChargeCancellationFee creates a payment session for the fee owed by a rider who cancelled a trip
+func (s *paymentService) ChargeCancellationFee(
+	ctx context.Context,
+	tripID string,
+	userID string,
+	driverID string,
+	fee int64,
+	currency string,
+) (*types.PaymentIntent, error) {
+	if fee <= 0 {
+		return nil, fmt.Errorf("cancellation fee must be positive, got %d", fee)
+	}
+
+	metadata := map[string]string{
+		"trip_id":      tripID,
+		"user_id":      userID,
+		"driver_id":    driverID,
+		"payment_type": messaging.PaymentTypeCancellationFee,
+	}
+
+	return s.createPaymentIntent(ctx, tripID, userID, driverID, fee, currency, types.PaymentKindCancellationFee, metadata)
+}
+
+// createPaymentIntent opens a checkout session and records its payment as pending. A
+// redelivered command finds the session it opened the first time instead of opening another.
+func (s *paymentService) createPaymentIntent(
+	ctx context.Context,
+	tripID string,
+	userID string,
+	driverID string,
+	amount int64,
+	currency string,
+	kind types.PaymentKind,
+	metadata map[string]string,
+) (*types.PaymentIntent, error) {
+	currency = strings.ToLower(currency)
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	payments, err := s.repo.ListPaymentsByTrip(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments of trip %s: %w", tripID, err)
+	}
+	for _, payment := range payments {
+		if payment.Kind == kind && payment.Status == types.PaymentStatusPending {
+			return paymentIntentOf(payment), nil
+		}
+	}
+
+	session, err := s.paymentProcessor.CreatePaymentSession(ctx, amount, currency, metadata)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create payment session: %w", err)
 	}
 
+	now := time.Now()
+
 	paymentIntent := &types.PaymentIntent{
 		ID:              uuid.New().String(),
 		TripID:          tripID,
 		UserID:          userID,
 		DriverID:        driverID,
 		Amount:          amount,
-		StripeSessionID: sessionID,
-		CreatedAt:       time.Now(),
+		Currency:        currency,
+		StripeSessionID: session.ID,
+		CheckoutURL:     session.URL,
+		CreatedAt:       now,
+	}
+
+	payment := &types.Payment{
+		ID:              paymentIntent.ID,
+		TripID:          tripID,
+		UserID:          userID,
+		DriverID:        driverID,
+		Kind:            kind,
+		Amount:          amount,
+		Currency:        paymentIntent.Currency,
+		Status:          types.PaymentStatusPending,
+		StripeSessionID: session.ID,
+		CheckoutURL:     session.URL,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := s.repo.SavePayment(ctx, payment); err != nil {
+		return nil, fmt.Errorf("failed to save payment: %w", err)
 	}
 
 	return paymentIntent, nil
 }
+
+func (s *paymentService) GetPayment(ctx context.Context, id string) (*types.Payment, error) {
+	return s.repo.GetPayment(ctx, id)
+}
+
+func (s *paymentService) GetPaymentByTrip(ctx context.Context, tripID string) (*types.Payment, error) {
+	return s.repo.GetLatestPaymentByTrip(ctx, tripID)
+}
+
+// ListPaymentsByUser pages through the payments of the user. The page token is the
+// offset of the page, one extra payment is fetched to know whether another page follows.
+func (s *paymentService) ListPaymentsByUser(ctx context.Context, userID string, pageSize int, pageToken string) (*types.PaymentPage, error) {
+	if pageSize <= 0 {
+		pageSize = defaultPaymentsPageSize
+	}
+	if pageSize > maxPaymentsPageSize {
+		pageSize = maxPaymentsPageSize
+	}
+
+	offset := 0
+	if pageToken != "" {
+		var err error
+		offset, err = strconv.Atoi(pageToken)
+		if err != nil || offset < 0 {
+			return nil, domain.ErrInvalidPageToken
+		}
+	}
+
+	payments, err := s.repo.ListPaymentsByUser(ctx, userID, offset, pageSize+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	page := &types.PaymentPage{Payments: payments}
+	if len(payments) > pageSize {
+		page.Payments = payments[:pageSize]
+		page.NextPageToken = strconv.Itoa(offset + pageSize)
+	}
+
+	return page, nil
+}
+
+// UpdatePaymentStatusBySession moves the payment of the session to the given status. Settling
+// a payment again with the status it already has is a no-op, so redelivered events are harmless.
+func (s *paymentService) UpdatePaymentStatusBySession(ctx context.Context, sessionID string, status types.PaymentStatus) (*types.Payment, error) {
+	payment, err := s.repo.GetPaymentBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.transition(ctx, payment, status); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (s *paymentService) CancelPendingRidePayments(ctx context.Context, tripID string) error {
+	payments, err := s.repo.ListPaymentsByTrip(ctx, tripID)
+	if err != nil {
+		return fmt.Errorf("failed to list payments of trip %s: %w", tripID, err)
+	}
+
+	for _, payment := range payments {
+		if payment.Kind != types.PaymentKindRide || payment.Status != types.PaymentStatusPending {
+			continue
+		}
+
+		if err := s.paymentProcessor.ExpirePaymentSession(ctx, payment.StripeSessionID); err != nil {
+			// the rider completed the checkout meanwhile, its webhook settles the payment
+			if errors.Is(err, domain.ErrSessionNotOpen) {
+				continue
+			}
+			return fmt.Errorf("failed to expire the session of payment %s: %w", payment.ID, err)
+		}
+
+		err := s.transition(ctx, payment, types.PaymentStatusCancelled)
+		if err != nil && !errors.Is(err, domain.ErrInvalidPaymentTransition) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *paymentService) RefundPayment(ctx context.Context, tripID string, kind types.PaymentKind) (*types.Payment, error) {
+	payments, err := s.repo.ListPaymentsByTrip(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments of trip %s: %w", tripID, err)
+	}
+
+	for _, payment := range payments {
+		if payment.Kind != kind {
+			continue
+		}
+
+		// a redelivered refund finds the payment already refunded
+		if payment.Status == types.PaymentStatusSuccess || payment.Status == types.PaymentStatusRefunded {
+			if err := s.transition(ctx, payment, types.PaymentStatusRefunded); err != nil {
+				return nil, err
+			}
+			return payment, nil
+		}
+	}
+
+	return nil, domain.ErrPaymentNotFound
+}
+
+// RetryRidePayment is safe to call again for the same failure: once the new session is
+// open, it returns that session instead of opening another one.
+func (s *paymentService) RetryRidePayment(ctx context.Context, tripID string) (*types.PaymentIntent, error) {
+	payments, err := s.repo.ListPaymentsByTrip(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments of trip %s: %w", tripID, err)
+	}
+
+	var latest *types.Payment
+	failures := 0
+	for _, payment := range payments {
+		if payment.Kind != types.PaymentKindRide {
+			continue
+		}
+
+		switch payment.Status {
+		case types.PaymentStatusFailed, types.PaymentStatusExpired:
+			failures++
+		case types.PaymentStatusPending:
+		default:
+			// paid, refunded or cancelled with the trip
+			return nil, domain.ErrPaymentNotRetryable
+		}
+
+		if latest == nil || payment.CreatedAt.After(latest.CreatedAt) {
+			latest = payment
+		}
+	}
+
+	if latest == nil || failures == 0 {
+		return nil, domain.ErrPaymentNotRetryable
+	}
+
+	if latest.Status == types.PaymentStatusPending {
+		return paymentIntentOf(latest), nil
+	}
+
+	if failures >= maxRidePaymentAttempts {
+		return nil, domain.ErrPaymentNotRetryable
+	}
+
+	return s.CreatePaymentSession(ctx, tripID, latest.UserID, latest.DriverID, latest.Amount, latest.Currency)
+}
+
+// HandleWebhook claims the event before publishing it, so that of deliveries racing each
+// other only one publishes. When publishing fails the claim is released and the provider's
+// retry of the delivery publishes the event then.
+func (s *paymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) (string, bool, error) {
+	event, err := s.paymentProcessor.ParseWebhookEvent(payload, signature)
+	if err != nil {
+		return "", false, err
+	}
+
+	if event.Status == "" {
+		// nothing to report for this event
+		return event.ID, false, nil
+	}
+
+	claimed, err := s.repo.ClaimWebhookEvent(ctx, event.ID, time.Now())
+	if err != nil {
+		return "", false, fmt.Errorf("failed to claim webhook event %s: %w", event.ID, err)
+	}
+	if !claimed {
+		return event.ID, true, nil
+	}
+
+	if err := s.publisher.PublishWebhookEvent(ctx, event); err != nil {
+		err = fmt.Errorf("failed to publish webhook event %s: %w", event.ID, err)
+		if releaseErr := s.repo.ReleaseWebhookEvent(ctx, event.ID); releaseErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to release webhook event %s: %w", event.ID, releaseErr))
+		}
+		return "", false, err
+	}
+
+	return event.ID, false, nil
+}
+
+func paymentIntentOf(payment *types.Payment) *types.PaymentIntent {
+	return &types.PaymentIntent{
+		ID:              payment.ID,
+		TripID:          payment.TripID,
+		UserID:          payment.UserID,
+		DriverID:        payment.DriverID,
+		Amount:          payment.Amount,
+		Currency:        payment.Currency,
+		StripeSessionID: payment.StripeSessionID,
+		CheckoutURL:     payment.CheckoutURL,
+		CreatedAt:       payment.CreatedAt,
+	}
+}
+
+// transition validates and stores the status change, updating the payment in place.
+func (s *paymentService) transition(ctx context.Context, payment *types.Payment, status types.PaymentStatus) error {
+	if payment.Status == status {
+		return nil
+	}
+
+	if !payment.Status.CanTransitionTo(status) {
+		return &domain.InvalidTransitionError{
+			PaymentID: payment.ID,
+			From:      payment.Status,
+			To:        status,
+		}
+	}
+
+	now := time.Now()
+	if err := s.repo.UpdatePaymentStatus(ctx, payment.ID, payment.Status, status, now); err != nil {
+		return fmt.Errorf("failed to update payment %s: %w", payment.ID, err)
+	}
+
+	payment.Status = status
+	payment.UpdatedAt = now
+	return nil
+}
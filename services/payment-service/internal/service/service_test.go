@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ride-sharing/services/payment-service/internal/domain"
+	"ride-sharing/services/payment-service/internal/infrastructure/fake"
+	"ride-sharing/services/payment-service/internal/infrastructure/repository"
+	"ride-sharing/services/payment-service/pkg/types"
+)
+
+type noopPublisher struct{}
+
+// expiryRecorder remembers the sessions the service expired.
+type expiryRecorder struct {
+	*fake.FakeProcessor
+	expired []string
+}
+
+func (p *expiryRecorder) ExpirePaymentSession(ctx context.Context, sessionID string) error {
+	p.expired = append(p.expired, sessionID)
+	return p.FakeProcessor.ExpirePaymentSession(ctx, sessionID)
+}
+
+// sessionCounter counts the checkout sessions the service opened.
+type sessionCounter struct {
+	*fake.FakeProcessor
+	opened int
+}
+
+func (p *sessionCounter) CreatePaymentSession(ctx context.Context, amount int64, currency string, metadata map[string]string) (*types.CheckoutSession, error) {
+	p.opened++
+	return p.FakeProcessor.CreatePaymentSession(ctx, amount, currency, metadata)
+}
+
+func (noopPublisher) PublishWebhookEvent(ctx context.Context, event *types.WebhookEvent) error {
+	return nil
+}
+
+func TestCancelPendingRidePaymentsExpiresTheSession(t *testing.T) {
+	ctx := context.Background()
+	processor := &expiryRecorder{FakeProcessor: fake.NewFakeProcessor(&types.PaymentConfig{}, "http://localhost:9005")}
+	svc := NewPaymentService(processor, repository.NewInmemRepository(), noopPublisher{})
+
+	intent, err := svc.CreatePaymentSession(ctx, "trip-1", "rider-1", "driver-1", 1250, "eur")
+	if err != nil {
+		t.Fatalf("CreatePaymentSession() = %v", err)
+	}
+
+	if err := svc.CancelPendingRidePayments(ctx, "trip-1"); err != nil {
+		t.Fatalf("CancelPendingRidePayments() = %v", err)
+	}
+
+	payment, err := svc.GetPayment(ctx, intent.ID)
+	if err != nil {
+		t.Fatalf("GetPayment() = %v", err)
+	}
+	if payment.Status != types.PaymentStatusCancelled {
+		t.Errorf("payment status = %q, want %q", payment.Status, types.PaymentStatusCancelled)
+	}
+
+	if len(processor.expired) != 1 || processor.expired[0] != intent.StripeSessionID {
+		t.Errorf("expired sessions = %v, want [%s]", processor.expired, intent.StripeSessionID)
+	}
+
+	// a redelivered cancellation finds nothing pending anymore
+	if err := svc.CancelPendingRidePayments(ctx, "trip-1"); err != nil {
+		t.Errorf("second CancelPendingRidePayments() = %v", err)
+	}
+	if len(processor.expired) != 1 {
+		t.Errorf("expired sessions = %v, want a single one", processor.expired)
+	}
+}
+
+func TestRedeliveredCommandsReuseThePendingSession(t *testing.T) {
+	ctx := context.Background()
+	processor := &sessionCounter{FakeProcessor: fake.NewFakeProcessor(&types.PaymentConfig{}, "http://localhost:9005")}
+	svc := NewPaymentService(processor, repository.NewInmemRepository(), noopPublisher{})
+
+	first, err := svc.CreatePaymentSession(ctx, "trip-1", "rider-1", "driver-1", 1250, "eur")
+	if err != nil {
+		t.Fatalf("CreatePaymentSession() = %v", err)
+	}
+	again, err := svc.CreatePaymentSession(ctx, "trip-1", "rider-1", "driver-1", 1250, "eur")
+	if err != nil {
+		t.Fatalf("second CreatePaymentSession() = %v", err)
+	}
+	if again.ID != first.ID || again.StripeSessionID != first.StripeSessionID {
+		t.Errorf("second CreatePaymentSession() = %s (%s), want %s (%s)", again.ID, again.StripeSessionID, first.ID, first.StripeSessionID)
+	}
+
+	// the cancellation fee of the same trip is a payment of its own
+	fee, err := svc.ChargeCancellationFee(ctx, "trip-1", "rider-1", "driver-1", 300, "eur")
+	if err != nil {
+		t.Fatalf("ChargeCancellationFee() = %v", err)
+	}
+	if fee.ID == first.ID {
+		t.Errorf("ChargeCancellationFee() reused the ride payment %s", first.ID)
+	}
+	if _, err := svc.ChargeCancellationFee(ctx, "trip-1", "rider-1", "driver-1", 300, "eur"); err != nil {
+		t.Fatalf("second ChargeCancellationFee() = %v", err)
+	}
+
+	if processor.opened != 2 {
+		t.Errorf("opened %d checkout sessions, want 2", processor.opened)
+	}
+}
+
+// paidEvent parses every webhook as the same payment success.
+type paidEvent struct {
+	domain.PaymentProcessor
+}
+
+func (paidEvent) ParseWebhookEvent(payload []byte, signature string) (*types.WebhookEvent, error) {
+	return &types.WebhookEvent{ID: "evt_1", Status: types.PaymentStatusSuccess, SessionID: "cs_1"}, nil
+}
+
+// gatedPublisher holds every publish until the gate opens, failing the first failures of them.
+type gatedPublisher struct {
+	gate     chan struct{}
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (p *gatedPublisher) PublishWebhookEvent(ctx context.Context, event *types.WebhookEvent) error {
+	<-p.gate
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("broker unavailable")
+	}
+	return nil
+}
+
+func TestConcurrentWebhookDeliveriesPublishOnce(t *testing.T) {
+	const deliveries = 5
+	publisher := &gatedPublisher{gate: make(chan struct{})}
+	svc := NewPaymentService(paidEvent{}, repository.NewInmemRepository(), publisher)
+
+	duplicates := make(chan bool, deliveries)
+	for range deliveries {
+		go func() {
+			_, duplicate, err := svc.HandleWebhook(context.Background(), []byte("{}"), "sig")
+			if err != nil {
+				t.Errorf("HandleWebhook() = %v", err)
+			}
+			duplicates <- duplicate
+		}()
+	}
+
+	// every delivery but the one holding the claim returns while it is publishing
+	for range deliveries - 1 {
+		select {
+		case duplicate := <-duplicates:
+			if !duplicate {
+				t.Fatal("a second delivery got past the claim")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("deliveries waited for the publish of another one")
+		}
+	}
+	close(publisher.gate)
+	if duplicate := <-duplicates; duplicate {
+		t.Error("the delivery holding the claim reported a duplicate")
+	}
+
+	if publisher.calls != 1 {
+		t.Errorf("published %d times, want once", publisher.calls)
+	}
+}
+
+func TestWebhookIsPublishedOnRetryAfterAFailedPublish(t *testing.T) {
+	ctx := context.Background()
+	publisher := &gatedPublisher{gate: make(chan struct{}), failures: 1}
+	close(publisher.gate)
+	svc := NewPaymentService(paidEvent{}, repository.NewInmemRepository(), publisher)
+
+	if _, _, err := svc.HandleWebhook(ctx, []byte("{}"), "sig"); err == nil {
+		t.Fatal("HandleWebhook() with the broker down = nil, want an error")
+	}
+
+	// the provider retries the delivery
+	if _, duplicate, err := svc.HandleWebhook(ctx, []byte("{}"), "sig"); err != nil || duplicate {
+		t.Fatalf("retried HandleWebhook() = duplicate %v, %v, want it published", duplicate, err)
+	}
+	if _, duplicate, err := svc.HandleWebhook(ctx, []byte("{}"), "sig"); err != nil || !duplicate {
+		t.Fatalf("HandleWebhook() after publishing = duplicate %v, %v, want a duplicate", duplicate, err)
+	}
+
+	if publisher.calls != 2 {
+		t.Errorf("tried to publish %d times, want 2", publisher.calls)
+	}
+}
@@ -9,20 +9,54 @@ const (
 	PaymentStatusPending   PaymentStatus = "pending"
 	PaymentStatusSuccess   PaymentStatus = "success"
 	PaymentStatusFailed    PaymentStatus = "failed"
-	PaymentStatusCancelled PaymentStatus = "cancelled"
+	PaymentStatusCancelled PaymentStatus = "cancelled" // the trip was cancelled before the payment
+	PaymentStatusExpired   PaymentStatus = "expired"   // the checkout session expired unpaid
+	PaymentStatusRefunded  PaymentStatus = "refunded"
+)
+
+// a payment is settled once it leaves pending, only a successful one can still be refunded
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending: {PaymentStatusSuccess, PaymentStatusFailed, PaymentStatusCancelled, PaymentStatusExpired},
+	PaymentStatusSuccess: {PaymentStatusRefunded},
+}
+
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// PaymentKind tells what a payment is for
+type PaymentKind string
+
+const (
+	PaymentKindRide            PaymentKind = "ride"
+	PaymentKindCancellationFee PaymentKind = "cancellation_fee"
 )
 
 // Payment represents a payment transaction
 type Payment struct {
-	ID              string        `json:"id"`
-	TripID          string        `json:"trip_id"`
-	UserID          string        `json:"user_id"`
-	Amount          int64         `json:"amount"`   // Amount in cents
-	Currency        string        `json:"currency"` // e.g., "usd"
-	Status          PaymentStatus `json:"status"`
-	StripeSessionID string        `json:"stripe_session_id"`
-	CreatedAt       time.Time     `json:"created_at"`
-	UpdatedAt       time.Time     `json:"updated_at"`
+	ID              string        `json:"id" bson:"_id"`
+	TripID          string        `json:"trip_id" bson:"trip_id"`
+	UserID          string        `json:"user_id" bson:"user_id"`
+	DriverID        string        `json:"driver_id" bson:"driver_id"`
+	Kind            PaymentKind   `json:"kind" bson:"kind"`
+	Amount          int64         `json:"amount" bson:"amount"`     // in minor units of Currency
+	Currency        string        `json:"currency" bson:"currency"` // e.g., "usd"
+	Status          PaymentStatus `json:"status" bson:"status"`
+	StripeSessionID string        `json:"stripe_session_id" bson:"stripe_session_id"`
+	CheckoutURL     string        `json:"checkout_url" bson:"checkout_url"`
+	CreatedAt       time.Time     `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at" bson:"updated_at"`
+}
+
+// PaymentPage is a page of payments and the token of the next one, empty on the last page
+type PaymentPage struct {
+	Payments      []*Payment
+	NextPageToken string
 }
 
 // PaymentIntent represents the intent to collect a payment
@@ -34,15 +68,34 @@ type PaymentIntent struct {
 	Amount          int64     `json:"amount"`
 	Currency        string    `json:"currency"`
 	StripeSessionID string    `json:"stripe_session_id"`
+	CheckoutURL     string    `json:"checkout_url"`
 	CreatedAt       time.Time `json:"created_at"`
 }
 
+// CheckoutSession is a session opened by the payment processor, paid by the rider at URL
+type CheckoutSession struct {
+	ID  string
+	URL string
+}
+
+// WebhookEvent is a webhook event of the payment provider, translated into the outcome of a
+// payment. Status is empty for the events that do not settle or refund a payment.
+type WebhookEvent struct {
+	ID        string        // the same on every delivery of the event
+	Status    PaymentStatus // success, failed, expired or refunded
+	SessionID string        // empty for refunds, which are reported per trip
+	TripID    string
+	UserID    string
+	DriverID  string
+	Kind      PaymentKind
+	Reason    string
+}
+
 // PaymentConfig holds the configuration for the payment service
 type PaymentConfig struct {
 	StripeSecretKey      string `json:"stripeSecretKey"`
 	StripePublishableKey string `json:"stripePublishableKey"`
 	StripeWebhookSecret  string `json:"stripeWebhookSecret"`
-	Currency             string `json:"currency"`
 	SuccessURL           string `json:"successURL"`
 	CancelURL            string `json:"cancelURL"`
 }
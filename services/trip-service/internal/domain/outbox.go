@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"ride-sharing/shared/contracts"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutboxEvent is a message waiting to be published. It is stored by the same repository
+// operation as the state change it announces, so that the change and the event are
+// either both saved or both lost, and the relay publishes it afterwards.
+type OutboxEvent struct {
+	ID          primitive.ObjectID    `bson:"_id"`
+	RoutingKey  string                `bson:"routingKey"`
+	Message     contracts.AmqpMessage `bson:"message"`
+	CreatedAt   time.Time             `bson:"createdAt"`
+	PublishedAt *time.Time            `bson:"publishedAt"`
+}
+
+func NewOutboxEvent(routingKey, ownerID string, payload any) (*OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s event: %w", routingKey, err)
+	}
+
+	id := primitive.NewObjectID()
+	now := time.Now().UTC()
+
+	return &OutboxEvent{
+		ID:         id,
+		RoutingKey: routingKey,
+		// the message ID is fixed here so that relaying the event twice yields a duplicate consumers can skip
+		Message: contracts.AmqpMessage{
+			MessageID: id.Hex(),
+			Timestamp: now,
+			OwnerID:   ownerID,
+			Data:      data,
+		},
+		CreatedAt: now,
+	}, nil
+}
+
+type OutboxRepository interface {
+	// SaveOutboxEvents stores events that do not come with a state change.
+	SaveOutboxEvents(ctx context.Context, events ...*OutboxEvent) error
+	// PendingOutboxEvents returns up to limit unpublished events, oldest first.
+	PendingOutboxEvents(ctx context.Context, limit int) ([]*OutboxEvent, error)
+	MarkOutboxEventPublished(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
@@ -1,21 +1,49 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	tripTypes "ride-sharing/services/trip-service/pkg/types"
 	tripGrpc "ride-sharing/shared/proto/trip"
+	"ride-sharing/shared/types"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type RideFareModel struct {
-	ID                primitive.ObjectID         `bson:"id"`
-	UserID            string                     `bson:"userId"`
-	PackageSlug       string                     `bson:"packageSlug"` // ex: van, luxury, sedan
-	TotalPriceInCents float64                    `bson:"totalPriceInCents"`
-	ExpiresAt         time.Time                  `bson:"expiresAt"`
-	Route             *tripTypes.OsrmApiResponse `bson:"route"`
+	ID                primitive.ObjectID `bson:"_id"`
+	UserID            string             `bson:"userId"`
+	PackageSlug       string             `bson:"packageSlug"`       // ex: van, luxury, sedan
+	TotalPriceInCents int64              `bson:"totalPriceInCents"` // in minor units of Currency
+	Currency          string             `bson:"currency"`
+	Breakdown         *FareBreakdown     `bson:"breakdown"`
+	SurgeMultiplier   float64            `bson:"surgeMultiplier"`
+	// charged when the rider cancels after a driver has been assigned, in minor units of Currency
+	CancellationFeeInCents int64                      `bson:"cancellationFeeInCents"`
+	ExpiresAt              time.Time                  `bson:"expiresAt"`
+	ConsumedAt             *time.Time                 `bson:"consumedAt"` // set once the fare has started a trip
+	Route                  *tripTypes.OsrmApiResponse `bson:"route"`
+}
+
+var (
+	ErrFareNotFound = errors.New("ride fare not found")
+	ErrFareNotOwned = errors.New("ride fare does not belong to user")
+	ErrFareExpired  = errors.New("ride fare has expired")
+	ErrFareConsumed = errors.New("ride fare has already been used")
+)
+
+// Validate checks that the fare can still start a trip at the given time.
+func (r *RideFareModel) Validate(now time.Time) error {
+	if r.ConsumedAt != nil {
+		return ErrFareConsumed
+	}
+
+	if !now.Before(r.ExpiresAt) {
+		return ErrFareExpired
+	}
+
+	return nil
 }
 
 func (r *RideFareModel) ToProto() *tripGrpc.RideFare {
@@ -24,9 +52,55 @@ func (r *RideFareModel) ToProto() *tripGrpc.RideFare {
 		UserId:            r.UserID,
 		PackageSlug:       r.PackageSlug,
 		TotalPriceInCents: r.TotalPriceInCents,
+		Currency:          r.Currency,
+		Breakdown:         r.Breakdown.ToProto(),
+		SurgeMultiplier:   r.SurgeMultiplier,
+	}
+}
+
+// FareBreakdown itemises how a fare total was computed, in minor units of the fare currency.
+type FareBreakdown struct {
+	BaseFare              int64 `bson:"baseFare"`
+	DistanceFare          int64 `bson:"distanceFare"`
+	TimeFare              int64 `bson:"timeFare"`
+	BookingFee            int64 `bson:"bookingFee"`
+	MinimumFareAdjustment int64 `bson:"minimumFareAdjustment"` // added to reach the package minimum fare
+	SurgeFare             int64 `bson:"surgeFare"`             // extra charged on the ride during high demand
+}
+
+func (b *FareBreakdown) Total() int64 {
+	return b.BaseFare + b.DistanceFare + b.TimeFare + b.BookingFee + b.MinimumFareAdjustment + b.SurgeFare
+}
+
+func (b *FareBreakdown) ToProto() *tripGrpc.FareBreakdown {
+	if b == nil {
+		return nil
+	}
+
+	return &tripGrpc.FareBreakdown{
+		BaseFare:              b.BaseFare,
+		DistanceFare:          b.DistanceFare,
+		TimeFare:              b.TimeFare,
+		BookingFee:            b.BookingFee,
+		MinimumFareAdjustment: b.MinimumFareAdjustment,
+		SurgeFare:             b.SurgeFare,
 	}
 }
 
+// Pickup returns the first point of the fare route, where the rider is picked up.
+func (r *RideFareModel) Pickup() (*types.Coordinate, bool) {
+	if r.Route == nil || len(r.Route.Routes) == 0 {
+		return nil, false
+	}
+
+	coords := r.Route.Routes[0].Geometry.Coordinates
+	if len(coords) == 0 || len(coords[0]) < 2 {
+		return nil, false
+	}
+
+	return &types.Coordinate{Latitude: coords[0][0], Longitude: coords[0][1]}, true
+}
+
 func ToRideFaresProto(fares []*RideFareModel) []*tripGrpc.RideFare {
 	if len(fares) == 0 {
 		return nil
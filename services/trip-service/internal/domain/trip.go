@@ -2,48 +2,114 @@ package domain
 
 import (
 	"context"
+	"errors"
 	tripTypes "ride-sharing/services/trip-service/pkg/types"
 	pbd "ride-sharing/shared/proto/driver"
 	pb "ride-sharing/shared/proto/trip"
 	"ride-sharing/shared/types"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TripModel struct {
-	ID       primitive.ObjectID `bson:"id"`
+	ID       primitive.ObjectID `bson:"_id"`
 	UserID   string             `bson:"user_id"`
-	Status   string             `bson:"status"`
+	Status   TripStatus         `bson:"status"`
 	RideFare *RideFareModel     `bson:"rideFare"`
 	Driver   *pb.TripDriver     `bson:"driver"`
+
+	PickupGeohash string    `bson:"pickupGeohash"` // used to measure demand around the pickup
+	CreatedAt     time.Time `bson:"createdAt"`
 }
 
 func (t *TripModel) ToProto() *pb.Trip {
 	return &pb.Trip{
 		Id:           t.ID.Hex(),
 		UserID:       t.UserID,
-		Status:       t.Status,
+		Status:       string(t.Status),
 		SelectedFare: t.RideFare.ToProto(),
 		Driver:       t.Driver,
 		Route:        t.RideFare.Route.ToProto(),
+		CreatedAt:    t.CreatedAt.Unix(),
 	}
 }
 
+var (
+	ErrTripNotFound       = errors.New("trip not found")
+	ErrNotTripParticipant = errors.New("user is neither the rider nor the driver of the trip")
+	ErrInvalidPageToken   = errors.New("invalid page token")
+	ErrTripAlreadyTaken   = errors.New("trip is no longer waiting for a driver")
+)
+
+// TripPage is one page of a trip listing, newest first.
+// NextPageToken is empty when there are no more trips.
+type TripPage struct {
+	Trips         []*TripModel
+	NextPageToken string
+}
+
+// TripCancellation is the outcome of a successful CancelTrip call.
+type TripCancellation struct {
+	Trip        *TripModel
+	CancelledBy string // messaging.CancelledByRider or messaging.CancelledByDriver
+	FeeInCents  int64
+}
+
 type TripRepository interface {
-	CreateTrip(ctx context.Context, trip *TripModel) (*TripModel, error)
+	OutboxRepository
+
+	// CreateTrip and UpdateTrip store the given outbox events atomically with the trip.
+	CreateTrip(ctx context.Context, trip *TripModel, events ...*OutboxEvent) (*TripModel, error)
 	GetTripByID(ctx context.Context, id string) (*TripModel, error)
-	UpdateTrip(ctx context.Context, tripID string, status string, driver *pbd.Driver) error
+	// UpdateTrip moves the trip from one status to another in a single atomic operation.
+	// It fails with an InvalidTransitionError if the trip is no longer in the from status,
+	// e.g. because a concurrent update got there first, and with ErrTripNotFound.
+	UpdateTrip(ctx context.Context, tripID string, from, to TripStatus, driver *pbd.Driver, events ...*OutboxEvent) error
 	SaveRideFare(ctx context.Context, fare *RideFareModel) error
 
 	GetRideFareByID(ctx context.Context, id string) (*RideFareModel, error)
+	// ConsumeRideFare atomically marks the fare as used, failing with ErrFareExpired
+	// or ErrFareConsumed if it can no longer start a trip.
+	ConsumeRideFare(ctx context.Context, id string, now time.Time) error
+	// DeleteExpiredRideFares removes the fares that expired before the given time.
+	DeleteExpiredRideFares(ctx context.Context, before time.Time) (int64, error)
+
+	// ListTripsByUser and ListTripsByDriver return at most limit trips, newest first,
+	// skipping the first offset ones.
+	ListTripsByUser(ctx context.Context, userID string, offset, limit int) ([]*TripModel, error)
+	ListTripsByDriver(ctx context.Context, driverID string, offset, limit int) ([]*TripModel, error)
+
+	// CountRequestedTrips counts the trips still waiting for a driver, per package,
+	// whose pickup geohash starts with cell.
+	CountRequestedTrips(ctx context.Context, cell string) (map[string]int, error)
+}
+
+var ErrNoRoute = errors.New("no route found between the given locations")
+
+// RouteProvider computes the driving route between two locations.
+type RouteProvider interface {
+	GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error)
+}
+
+// DriverSupply reports how many drivers are available, per package, in a geohash cell.
+type DriverSupply interface {
+	AvailableDrivers(ctx context.Context, cell string) (map[string]int, error)
 }
 
 type TripService interface {
 	CreateTrip(ctx context.Context, fare *RideFareModel) (*TripModel, error)
 	GetTripByID(ctx context.Context, id string) (*TripModel, error)
-	UpdateTrip(ctx context.Context, tripID string, status string, driver *pbd.Driver) error
+	ListTripsByUser(ctx context.Context, userID string, pageSize int, pageToken string) (*TripPage, error)
+	ListTripsByDriver(ctx context.Context, driverID string, pageSize int, pageToken string) (*TripPage, error)
+	UpdateTrip(ctx context.Context, tripID string, status TripStatus, driver *pbd.Driver) error
+	// AssignDriver gives a requested trip to the driver who accepted it.
+	AssignDriver(ctx context.Context, tripID string, driver *pbd.Driver) (*TripModel, error)
+	// DeclineTrip lets dispatch know that the driver does not want the trip.
+	DeclineTrip(ctx context.Context, tripID, driverID string) error
+	CancelTrip(ctx context.Context, tripID, userID string) (*TripCancellation, error)
 	GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error)
-	EstimatePackagesPriceWithRoute(route *tripTypes.OsrmApiResponse) []*RideFareModel
+	EstimatePackagesPriceWithRoute(ctx context.Context, pickup *types.Coordinate, route *tripTypes.OsrmApiResponse) []*RideFareModel
 	GenerateTripFares(ctx context.Context, fares []*RideFareModel, userID string, route *tripTypes.OsrmApiResponse) ([]*RideFareModel, error)
 
 	GetAndValidateFare(ctx context.Context, fareID, userID string) (*RideFareModel, error)
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TripStatus is the lifecycle state of a trip.
+type TripStatus string
+
+const (
+	TripStatusRequested      TripStatus = "requested"
+	TripStatusDriverAssigned TripStatus = "driver_assigned"
+	TripStatusDriverArriving TripStatus = "driver_arriving"
+	TripStatusInProgress     TripStatus = "in_progress"
+	TripStatusCompleted      TripStatus = "completed"
+	TripStatusPaid           TripStatus = "paid"
+	TripStatusPaymentFailed  TripStatus = "payment_failed"
+	TripStatusRefunded       TripStatus = "refunded"
+	TripStatusCancelled      TripStatus = "cancelled"
+	TripStatusNoDrivers      TripStatus = "no_drivers"
+)
+
+// tripTransitions lists, for every status, the statuses a trip is allowed to move to.
+// Payment is collected as soon as a driver is assigned, so a trip can become paid, or see
+// its payment fail, from any active state. A failed payment can be retried until the trip
+// is paid or cancelled. Refunded, cancelled and no_drivers are terminal.
+var tripTransitions = map[TripStatus][]TripStatus{
+	TripStatusRequested: {
+		TripStatusDriverAssigned,
+		TripStatusNoDrivers,
+		TripStatusCancelled,
+	},
+	TripStatusDriverAssigned: {
+		TripStatusDriverArriving,
+		TripStatusInProgress,
+		TripStatusPaid,
+		TripStatusPaymentFailed,
+		TripStatusCancelled,
+	},
+	TripStatusDriverArriving: {
+		TripStatusInProgress,
+		TripStatusPaid,
+		TripStatusPaymentFailed,
+		TripStatusCancelled,
+	},
+	TripStatusInProgress: {
+		TripStatusCompleted,
+		TripStatusPaid,
+		TripStatusPaymentFailed,
+	},
+	TripStatusCompleted: {
+		TripStatusPaid,
+		TripStatusPaymentFailed,
+	},
+	TripStatusPaymentFailed: {
+		TripStatusPaid,
+		TripStatusCancelled,
+	},
+	TripStatusPaid: {
+		TripStatusRefunded,
+	},
+	TripStatusRefunded:  {},
+	TripStatusCancelled: {},
+	TripStatusNoDrivers: {},
+}
+
+var ErrInvalidTripTransition = errors.New("invalid trip status transition")
+
+// InvalidTransitionError is returned when a trip is asked to move to a status
+// that is not reachable from its current one.
+type InvalidTransitionError struct {
+	TripID string
+	From   TripStatus
+	To     TripStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("trip %s cannot move from %q to %q", e.TripID, e.From, e.To)
+}
+
+func (e *InvalidTransitionError) Is(target error) bool {
+	return target == ErrInvalidTripTransition
+}
+
+func (s TripStatus) CanTransitionTo(next TripStatus) bool {
+	for _, allowed := range tripTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns an InvalidTransitionError if the trip cannot move to next.
+func (t *TripModel) ValidateTransition(next TripStatus) error {
+	if !t.Status.CanTransitionTo(next) {
+		return &InvalidTransitionError{
+			TripID: t.ID.Hex(),
+			From:   t.Status,
+			To:     next,
+		}
+	}
+	return nil
+}
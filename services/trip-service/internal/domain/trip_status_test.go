@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to TripStatus
+		allowed  bool
+	}{
+		{TripStatusRequested, TripStatusDriverAssigned, true},
+		{TripStatusRequested, TripStatusNoDrivers, true},
+		{TripStatusRequested, TripStatusCancelled, true},
+		{TripStatusRequested, TripStatusInProgress, false},
+		{TripStatusRequested, TripStatusPaid, false},
+		{TripStatusDriverAssigned, TripStatusDriverArriving, true},
+		{TripStatusDriverAssigned, TripStatusPaid, true},
+		{TripStatusDriverAssigned, TripStatusPaymentFailed, true},
+		{TripStatusDriverAssigned, TripStatusRequested, false},
+		{TripStatusDriverAssigned, TripStatusDriverAssigned, false},
+		{TripStatusDriverArriving, TripStatusCancelled, true},
+		{TripStatusInProgress, TripStatusCompleted, true},
+		{TripStatusInProgress, TripStatusCancelled, false},
+		{TripStatusCompleted, TripStatusPaid, true},
+		{TripStatusCompleted, TripStatusCancelled, false},
+		{TripStatusPaymentFailed, TripStatusPaid, true},
+		{TripStatusPaymentFailed, TripStatusCancelled, true},
+		{TripStatusPaymentFailed, TripStatusRefunded, false},
+		{TripStatusPaid, TripStatusRefunded, true},
+		{TripStatusPaid, TripStatusCancelled, false},
+		{TripStatusRefunded, TripStatusPaid, false},
+		{TripStatusCancelled, TripStatusRequested, false},
+		{TripStatusNoDrivers, TripStatusDriverAssigned, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			trip := &TripModel{ID: primitive.NewObjectID(), Status: tt.from}
+
+			err := trip.ValidateTransition(tt.to)
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("ValidateTransition() = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidTripTransition) {
+				t.Fatalf("ValidateTransition() = %v, want ErrInvalidTripTransition", err)
+			}
+
+			var transitionErr *InvalidTransitionError
+			if !errors.As(err, &transitionErr) || transitionErr.From != tt.from || transitionErr.To != tt.to {
+				t.Errorf("ValidateTransition() = %#v, want a transition from %q to %q", err, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestTerminalStatuses(t *testing.T) {
+	for _, status := range []TripStatus{TripStatusRefunded, TripStatusCancelled, TripStatusNoDrivers} {
+		if next := tripTransitions[status]; len(next) != 0 {
+			t.Errorf("%q is terminal but can move to %v", status, next)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"ride-sharing/services/trip-service/internal/domain"
 	"ride-sharing/shared/contracts"
@@ -24,6 +25,10 @@ func NewDriverConsumer(rabbitMQ *messaging.RabbitMQ, service domain.TripService)
 }
 
 func (c *driverConsumer) Listen() error {
+	if err := c.rabbitMQ.ConsumeMessages(messaging.TripNoDriversFoundQueue, c.handleNoDriversFound); err != nil {
+		return err
+	}
+
 	return c.rabbitMQ.ConsumeMessages(messaging.DriverTripResponseQueue, func(ctx context.Context, msg amqp091.Delivery) error {
 		// Handle the incoming message
 		var tripEvent contracts.AmqpMessage
@@ -59,74 +64,54 @@ func (c *driverConsumer) Listen() error {
 }
 
 func (c *driverConsumer) handleTripAccepted(ctx context.Context, payload *messaging.DriverTripResponseData) error {
-
-	trip, err := c.service.GetTripByID(ctx, payload.TripID)
-	if err != nil || trip == nil {
-		log.Printf("failed to get trip by ID: %v", err)
-		return err
-	}
-
-	if err := c.service.UpdateTrip(ctx, payload.TripID, "accepted", &payload.Driver); err != nil {
-		log.Printf("failed to update trip status: %v", err)
-		return err
-	}
-
-	trip, err = c.service.GetTripByID(ctx, payload.TripID)
-	if err != nil || trip == nil {
-		log.Printf("failed to get trip by ID: %v", err)
-		return err
-	}
-
-	marshalTrip, err := json.Marshal(trip)
-
+	trip, err := c.service.AssignDriver(ctx, payload.TripID, &payload.Driver)
 	if err != nil {
-		log.Printf("failed to marshal trip: %v", err)
+		if errors.Is(err, domain.ErrTripNotFound) || errors.Is(err, domain.ErrTripAlreadyTaken) || errors.Is(err, domain.ErrInvalidTripTransition) {
+			log.Printf("ignoring acceptance of trip %s by driver %s: %v", payload.TripID, payload.Driver.GetId(), err)
+			return nil
+		}
+		log.Printf("failed to assign driver: %v", err)
 		return err
 	}
 
-	// notify the reider that driver has been assigned
-	if err := c.rabbitMQ.PublishMessage(ctx, contracts.TripEventDriverAssigned, &contracts.AmqpMessage{
-		OwnerID: trip.UserID,
-		Data:    marshalTrip,
-	}); err != nil {
-		log.Printf("failed to publish trip response: %v", err)
-		return err
-	}
+	log.Printf("Trip %s assigned to driver %s", trip.ID.Hex(), payload.Driver.GetId())
+
+	return nil
+}
 
-	if err := c.rabbitMQ.PublishMessage(ctx, contracts.PaymentCmdCreateSession, &contracts.AmqpMessage{
-		OwnerID: trip.UserID,
-		Data:    marshalTrip,
-	}); err != nil {
-		log.Printf("failed to publish payment command: %v", err)
+func (c *driverConsumer) handleTripDeclined(ctx context.Context, tripID, driverID string) error {
+	if err := c.service.DeclineTrip(ctx, tripID, driverID); err != nil {
+		// a decline that arrives after the trip left the requested state must not restart dispatch
+		if errors.Is(err, domain.ErrTripAlreadyTaken) {
+			log.Printf("ignoring decline for trip %s: %v", tripID, err)
+			return nil
+		}
 		return err
 	}
 
 	return nil
 }
 
-func (c *driverConsumer) handleTripDeclined(ctx context.Context, tripID, riderID string) error {
-	// When a driver declines, we should try to find another driver
-
-	trip, err := c.service.GetTripByID(ctx, tripID)
-	if err != nil {
+// handleNoDriversFound closes a trip that driver-service gave up dispatching
+func (c *driverConsumer) handleNoDriversFound(ctx context.Context, msg amqp091.Delivery) error {
+	var tripEvent contracts.AmqpMessage
+	if err := json.Unmarshal(msg.Body, &tripEvent); err != nil {
+		log.Printf("failed to unmarshal message: %v", err)
 		return err
 	}
 
-	newPayload := messaging.TripEventData{
-		Trip: trip.ToProto(),
-	}
-
-	marshalledPayload, err := json.Marshal(newPayload)
-	if err != nil {
+	var payload messaging.TripEventData
+	if err := json.Unmarshal(tripEvent.Data, &payload); err != nil {
+		log.Printf("failed to unmarshal message: %v", err)
 		return err
 	}
 
-	if err := c.rabbitMQ.PublishMessage(ctx, contracts.TripEventDriverNotInterested,
-		&contracts.AmqpMessage{
-			OwnerID: riderID,
-			Data:    marshalledPayload,
-		},
-	); err != nil {
+	if err := c.service.UpdateTrip(ctx, payload.Trip.GetId(), domain.TripStatusNoDrivers, nil); err != nil {
+		if errors.Is(err, domain.ErrInvalidTripTransition) {
+			log.Printf("ignoring no drivers found: %v", err)
+			return nil
+		}
+		log.Printf("failed to update trip status: %v", err)
 		return err
 	}
 
@@ -0,0 +1,77 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/shared/messaging"
+	"time"
+)
+
+const outboxBatchSize = 100
+
+// outboxRelay publishes the events stored in the outbox. An event is marked as published
+// only after RabbitMQ confirmed it, so a crash in between publishes it again: delivery is
+// at least once and consumers must tolerate duplicates. Events are relayed in order and
+// a failed publish stops the batch, so a single relay must run per outbox.
+type outboxRelay struct {
+	repo     domain.OutboxRepository
+	rabbitmq *messaging.RabbitMQ
+	interval time.Duration
+}
+
+func NewOutboxRelay(repo domain.OutboxRepository, rabbitmq *messaging.RabbitMQ, interval time.Duration) *outboxRelay {
+	return &outboxRelay{
+		repo:     repo,
+		rabbitmq: rabbitmq,
+		interval: interval,
+	}
+}
+
+// Run relays pending events every interval until the context is cancelled.
+func (r *outboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.relay(ctx); err != nil {
+				log.Printf("failed to relay outbox events: %v", err)
+			}
+		}
+	}
+}
+
+// relay publishes pending events until the outbox is empty or a publish fails.
+func (r *outboxRelay) relay(ctx context.Context) error {
+	for {
+		events, err := r.repo.PendingOutboxEvents(ctx, outboxBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			message := event.Message
+			err := r.rabbitmq.PublishMessage(ctx, event.RoutingKey, &message)
+			switch {
+			case errors.Is(err, messaging.ErrUnroutable):
+				// no queue is bound to the key, retrying would block the outbox forever
+				log.Printf("dropping outbox event %s: %v", event.ID.Hex(), err)
+			case err != nil:
+				return err
+			}
+
+			if err := r.repo.MarkOutboxEventPublished(ctx, event.ID, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		if len(events) < outboxBatchSize {
+			return nil
+		}
+	}
+}
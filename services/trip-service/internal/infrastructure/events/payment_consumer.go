@@ -3,6 +3,7 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 
 	"ride-sharing/services/trip-service/internal/domain"
@@ -24,8 +25,16 @@ func NewPaymentConsumer(rabbitmq *messaging.RabbitMQ, service domain.TripService
 	}
 }
 
+// tripStatusAfterPayment maps the outcome of a ride payment to the status of its trip
+var tripStatusAfterPayment = map[string]domain.TripStatus{
+	contracts.PaymentEventSuccess:   domain.TripStatusPaid,
+	contracts.PaymentEventFailed:    domain.TripStatusPaymentFailed,
+	contracts.PaymentEventCancelled: domain.TripStatusPaymentFailed,
+	contracts.PaymentEventRefunded:  domain.TripStatusRefunded,
+}
+
 func (c *paymentConsumer) Listen() error {
-	return c.rabbitmq.ConsumeMessages(messaging.NotifyPaymentSuccessQueue, func(ctx context.Context, msg amqp091.Delivery) error {
+	return c.rabbitmq.ConsumeMessages(messaging.TripPaymentStatusQueue, func(ctx context.Context, msg amqp091.Delivery) error {
 		var message contracts.AmqpMessage
 		if err := json.Unmarshal(msg.Body, &message); err != nil {
 			log.Printf("Failed to unmarshal message: %v", err)
@@ -37,13 +46,31 @@ func (c *paymentConsumer) Listen() error {
 			return err
 		}
 
-		log.Printf("Trip has been completed and payed.")
+		// cancellation fees are paid for trips that are already over
+		if payload.PaymentType == messaging.PaymentTypeCancellationFee {
+			return nil
+		}
+
+		status, ok := tripStatusAfterPayment[msg.RoutingKey]
+		if !ok {
+			return nil
+		}
 
-		return c.service.UpdateTrip(
+		if err := c.service.UpdateTrip(
 			ctx,
 			payload.TripID,
-			"payed",
+			status,
 			nil,
-		)
+		); err != nil {
+			if errors.Is(err, domain.ErrInvalidTripTransition) {
+				log.Printf("Ignoring payment event: %v", err)
+				return nil
+			}
+			return err
+		}
+
+		log.Printf("Trip %s is now %s.", payload.TripID, status)
+
+		return nil
 	})
 }
@@ -2,8 +2,9 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"ride-sharing/services/trip-service/internal/domain"
-	"ride-sharing/services/trip-service/internal/infrastructure/events"
+	"ride-sharing/shared/auth"
 	pb "ride-sharing/shared/proto/trip"
 	"ride-sharing/shared/types"
 
@@ -14,14 +15,12 @@ import (
 
 type gRPCHandler struct {
 	pb.UnimplementedTripServiceServer
-	service   domain.TripService
-	publisher *events.TripEventPublisher
+	service domain.TripService
 }
 
-func NewGRPCHandler(server *grpc.Server, service domain.TripService, publisher *events.TripEventPublisher) *gRPCHandler {
+func NewGRPCHandler(server *grpc.Server, service domain.TripService) *gRPCHandler {
 	handler := &gRPCHandler{
-		service:   service,
-		publisher: publisher,
+		service: service,
 	}
 
 	pb.RegisterTripServiceServer(server, handler)
@@ -29,6 +28,11 @@ func NewGRPCHandler(server *grpc.Server, service domain.TripService, publisher *
 }
 
 func (h *gRPCHandler) PreviewTrip(ctx context.Context, req *pb.PreviewTripRequest) (*pb.PreviewTripResponse, error) {
+	userID, err := auth.IncomingUserID(ctx, req.GetUserID())
+	if err != nil {
+		return nil, err
+	}
+
 	pickup := req.GetStartLocation()
 	destination := req.GetEndLocation()
 
@@ -47,9 +51,7 @@ func (h *gRPCHandler) PreviewTrip(ctx context.Context, req *pb.PreviewTripReques
 		return nil, status.Errorf(codes.Aborted, "failed to get route: %v", err)
 	}
 
-	userID := req.GetUserID()
-
-	estimatedFares := h.service.EstimatePackagesPriceWithRoute(route)
+	estimatedFares := h.service.EstimatePackagesPriceWithRoute(ctx, pickupCoord, route)
 
 	fares, err := h.service.GenerateTripFares(ctx, estimatedFares, userID, route)
 	if err != nil {
@@ -63,21 +65,140 @@ func (h *gRPCHandler) PreviewTrip(ctx context.Context, req *pb.PreviewTripReques
 }
 
 func (h *gRPCHandler) CreateTrip(ctx context.Context, req *pb.CreateTripRequest) (*pb.CreateTripResponse, error) {
-	rideFare, err := h.service.GetAndValidateFare(ctx, req.GetRideFareID(), req.GetUserID())
+	userID, err := auth.IncomingUserID(ctx, req.GetUserID())
 	if err != nil {
-		return nil, status.Errorf(codes.Aborted, "failed to get and validate fare: %v", err)
+		return nil, err
 	}
 
-	trip, err := h.service.CreateTrip(ctx, rideFare)
+	rideFare, err := h.service.GetAndValidateFare(ctx, req.GetRideFareID(), userID)
 	if err != nil {
-		return nil, status.Errorf(codes.Aborted, "failed to create trip: %v", err)
+		return nil, fareError("failed to get and validate fare", err)
 	}
 
-	if err := h.publisher.PublishTripCreated(ctx, trip); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to publish trip created event: %v", err)
+	trip, err := h.service.CreateTrip(ctx, rideFare)
+	if err != nil {
+		return nil, fareError("failed to create trip", err)
 	}
 
 	return &pb.CreateTripResponse{
 		TripID: trip.ID.Hex(),
 	}, nil
 }
+
+func (h *gRPCHandler) CancelTrip(ctx context.Context, req *pb.CancelTripRequest) (*pb.CancelTripResponse, error) {
+	if req.GetTripID() == "" {
+		return nil, status.Error(codes.InvalidArgument, "trip ID is required")
+	}
+
+	userID, err := auth.IncomingUserID(ctx, req.GetUserID())
+	if err != nil {
+		return nil, err
+	}
+
+	cancellation, err := h.service.CancelTrip(ctx, req.GetTripID(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTripNotFound):
+			return nil, status.Errorf(codes.NotFound, "failed to cancel trip: %v", err)
+		case errors.Is(err, domain.ErrNotTripParticipant):
+			return nil, status.Errorf(codes.PermissionDenied, "failed to cancel trip: %v", err)
+		case errors.Is(err, domain.ErrInvalidTripTransition):
+			return nil, status.Errorf(codes.FailedPrecondition, "failed to cancel trip: %v", err)
+		}
+		return nil, status.Errorf(codes.Aborted, "failed to cancel trip: %v", err)
+	}
+
+	return &pb.CancelTripResponse{
+		Trip:                   cancellation.Trip.ToProto(),
+		CancelledBy:            cancellation.CancelledBy,
+		CancellationFeeInCents: cancellation.FeeInCents,
+	}, nil
+}
+
+func (h *gRPCHandler) GetTrip(ctx context.Context, req *pb.GetTripRequest) (*pb.GetTripResponse, error) {
+	if req.GetTripID() == "" {
+		return nil, status.Error(codes.InvalidArgument, "trip ID is required")
+	}
+
+	userID, err := auth.IncomingUserID(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	trip, err := h.service.GetTripByID(ctx, req.GetTripID())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get trip: %v", err)
+	}
+
+	// only the rider and the driver of the trip may see it, other users cannot tell it exists
+	if trip == nil || (trip.UserID != userID && trip.Driver.GetId() != userID) {
+		return nil, status.Errorf(codes.NotFound, "trip %s not found", req.GetTripID())
+	}
+
+	return &pb.GetTripResponse{
+		Trip: trip.ToProto(),
+	}, nil
+}
+
+func (h *gRPCHandler) ListTripsByUser(ctx context.Context, req *pb.ListTripsByUserRequest) (*pb.ListTripsResponse, error) {
+	userID, err := auth.IncomingUserID(ctx, req.GetUserID())
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.service.ListTripsByUser(ctx, userID, int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		return nil, listTripsError(err)
+	}
+
+	return tripPageToProto(page), nil
+}
+
+func (h *gRPCHandler) ListTripsByDriver(ctx context.Context, req *pb.ListTripsByDriverRequest) (*pb.ListTripsResponse, error) {
+	driverID, err := auth.IncomingUserID(ctx, req.GetDriverID())
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.service.ListTripsByDriver(ctx, driverID, int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		return nil, listTripsError(err)
+	}
+
+	return tripPageToProto(page), nil
+}
+
+func listTripsError(err error) error {
+	if errors.Is(err, domain.ErrInvalidPageToken) {
+		return status.Errorf(codes.InvalidArgument, "failed to list trips: %v", err)
+	}
+	return status.Errorf(codes.Internal, "failed to list trips: %v", err)
+}
+
+func tripPageToProto(page *domain.TripPage) *pb.ListTripsResponse {
+	trips := make([]*pb.Trip, 0, len(page.Trips))
+	for _, trip := range page.Trips {
+		trips = append(trips, trip.ToProto())
+	}
+
+	return &pb.ListTripsResponse{
+		Trips:         trips,
+		NextPageToken: page.NextPageToken,
+	}
+}
+
+// fareError maps fare validation failures to distinct status codes so that clients
+// can tell an expired quote, which needs a new preview, from a fare already used.
+func fareError(msg string, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrFareNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", msg, err)
+	case errors.Is(err, domain.ErrFareNotOwned):
+		return status.Errorf(codes.PermissionDenied, "%s: %v", msg, err)
+	case errors.Is(err, domain.ErrFareExpired):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", msg, err)
+	case errors.Is(err, domain.ErrFareConsumed):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", msg, err)
+	}
+	return status.Errorf(codes.Aborted, "%s: %v", msg, err)
+}
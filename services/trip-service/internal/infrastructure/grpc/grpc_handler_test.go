@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"ride-sharing/services/trip-service/internal/domain"
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+	"ride-sharing/shared/auth"
+	pb "ride-sharing/shared/proto/trip"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tripLookup only answers GetTripByID.
+type tripLookup struct {
+	domain.TripService
+	trips map[string]*domain.TripModel
+}
+
+func (s *tripLookup) GetTripByID(ctx context.Context, id string) (*domain.TripModel, error) {
+	return s.trips[id], nil
+}
+
+func asUser(userID string, role auth.Role) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		auth.UserIDMetadataKey, userID,
+		auth.RoleMetadataKey, string(role),
+	))
+}
+
+func TestGetTrip(t *testing.T) {
+	trip := &domain.TripModel{
+		ID:       primitive.NewObjectID(),
+		UserID:   "rider-1",
+		Status:   domain.TripStatusDriverAssigned,
+		RideFare: &domain.RideFareModel{Route: &tripTypes.OsrmApiResponse{}},
+		Driver:   &pb.TripDriver{Id: "driver-1"},
+	}
+	handler := &gRPCHandler{service: &tripLookup{trips: map[string]*domain.TripModel{trip.ID.Hex(): trip}}}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		tripID string
+		want   codes.Code
+	}{
+		{"rider", asUser("rider-1", auth.RoleRider), trip.ID.Hex(), codes.OK},
+		{"driver", asUser("driver-1", auth.RoleDriver), trip.ID.Hex(), codes.OK},
+		{"another rider", asUser("rider-2", auth.RoleRider), trip.ID.Hex(), codes.NotFound},
+		{"unknown trip", asUser("rider-1", auth.RoleRider), primitive.NewObjectID().Hex(), codes.NotFound},
+		{"no trip ID", asUser("rider-1", auth.RoleRider), "", codes.InvalidArgument},
+		{"no authenticated user", context.Background(), trip.ID.Hex(), codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := handler.GetTrip(tt.ctx, &pb.GetTripRequest{TripID: tt.tripID})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("GetTrip() = %v, want %s", err, tt.want)
+			}
+			if tt.want == codes.OK && resp.GetTrip().GetId() != trip.ID.Hex() {
+				t.Errorf("GetTrip() = %v, want trip %s", resp.GetTrip(), trip.ID.Hex())
+			}
+		})
+	}
+}
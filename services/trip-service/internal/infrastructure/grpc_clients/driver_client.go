@@ -0,0 +1,58 @@
+package grpc_clients
+
+import (
+	"context"
+	"os"
+	driverGrpc "ride-sharing/shared/proto/driver"
+	"ride-sharing/shared/tracing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type driverServiceClient struct {
+	Client driverGrpc.DriverServiceClient
+	conn   *grpc.ClientConn
+}
+
+func NewDriverServiceClient() (*driverServiceClient, error) {
+	driverServiceUrl := os.Getenv("DRIVER_SERVICE_URL")
+	if driverServiceUrl == "" {
+		driverServiceUrl = "driver-service:9092"
+	}
+
+	dialOptions := append(tracing.DialOptionsWithTracing(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(driverServiceUrl, dialOptions...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := driverGrpc.NewDriverServiceClient(conn)
+
+	return &driverServiceClient{
+		Client: client,
+		conn:   conn,
+	}, nil
+}
+
+// AvailableDrivers implements domain.DriverSupply with the driver-service registry.
+func (c *driverServiceClient) AvailableDrivers(ctx context.Context, cell string) (map[string]int, error) {
+	res, err := c.Client.GetDriverSupply(ctx, &driverGrpc.GetDriverSupplyRequest{Geohash: cell})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(res.GetAvailableDrivers()))
+	for slug, count := range res.GetAvailableDrivers() {
+		counts[slug] = int(count)
+	}
+
+	return counts, nil
+}
+
+func (c *driverServiceClient) Close() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
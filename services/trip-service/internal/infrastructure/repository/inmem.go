@@ -2,15 +2,22 @@ package repository
 
 import (
 	"context"
-	"fmt"
 	"ride-sharing/services/trip-service/internal/domain"
 	pbd "ride-sharing/shared/proto/driver"
 	pb "ride-sharing/shared/proto/trip"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type inmemRepository struct {
+	mu        sync.RWMutex
 	trips     map[string]*domain.TripModel
 	rideFares map[string]*domain.RideFareModel
+	outbox    []*domain.OutboxEvent // unpublished events, oldest first
 }
 
 func NewInmemRepository() *inmemRepository {
@@ -20,27 +27,60 @@ func NewInmemRepository() *inmemRepository {
 	}
 }
 
-func (r *inmemRepository) CreateTrip(ctx context.Context, trip *domain.TripModel) (*domain.TripModel, error) {
-	r.trips[trip.ID.Hex()] = trip
+func (r *inmemRepository) CreateTrip(ctx context.Context, trip *domain.TripModel, events ...*domain.OutboxEvent) (*domain.TripModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.trips[trip.ID.Hex()] = copyTrip(trip)
+	r.outbox = append(r.outbox, events...)
 
 	return trip, nil
 }
 
+// copyTrip copies the trip and its fare, so that callers never share the stored ones
+// and every change goes through the repository, like with a database.
+func copyTrip(trip *domain.TripModel) *domain.TripModel {
+	c := *trip
+	if trip.RideFare != nil {
+		c.RideFare = copyRideFare(trip.RideFare)
+	}
+	return &c
+}
+
+func copyRideFare(fare *domain.RideFareModel) *domain.RideFareModel {
+	c := *fare
+	return &c
+}
+
 func (r *inmemRepository) GetTripByID(ctx context.Context, id string) (*domain.TripModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	trip, ok := r.trips[id]
 	if !ok {
 		return nil, nil
 	}
-	return trip, nil
+	return copyTrip(trip), nil
 }
 
-func (r *inmemRepository) UpdateTrip(ctx context.Context, tripID string, status string, driver *pbd.Driver) error {
+func (r *inmemRepository) UpdateTrip(ctx context.Context, tripID string, from, to domain.TripStatus, driver *pbd.Driver, events ...*domain.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	trip, ok := r.trips[tripID]
 	if !ok {
-		return fmt.Errorf("trip not found with ID: %s", tripID)
+		return domain.ErrTripNotFound
+	}
+
+	if trip.Status != from {
+		return &domain.InvalidTransitionError{
+			TripID: tripID,
+			From:   trip.Status,
+			To:     to,
+		}
 	}
 
-	trip.Status = status
+	trip.Status = to
 
 	if driver != nil {
 		trip.Driver = &pb.TripDriver{
@@ -50,18 +90,141 @@ func (r *inmemRepository) UpdateTrip(ctx context.Context, tripID string, status
 			ProfilePicture: driver.ProfilePicture,
 		}
 	}
+
+	r.outbox = append(r.outbox, events...)
+	return nil
+}
+
+func (r *inmemRepository) SaveOutboxEvents(ctx context.Context, events ...*domain.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.outbox = append(r.outbox, events...)
+	return nil
+}
+
+func (r *inmemRepository) PendingOutboxEvents(ctx context.Context, limit int) ([]*domain.OutboxEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := min(limit, len(r.outbox))
+	events := make([]*domain.OutboxEvent, n)
+	copy(events, r.outbox[:n])
+	return events, nil
+}
+
+// MarkOutboxEventPublished drops the event, nothing reads published events from memory.
+func (r *inmemRepository) MarkOutboxEventPublished(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, event := range r.outbox {
+		if event.ID == id {
+			r.outbox = append(r.outbox[:i], r.outbox[i+1:]...)
+			return nil
+		}
+	}
 	return nil
 }
 
 func (r *inmemRepository) SaveRideFare(ctx context.Context, fare *domain.RideFareModel) error {
-	r.rideFares[fare.ID.Hex()] = fare
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rideFares[fare.ID.Hex()] = copyRideFare(fare)
 	return nil
 }
 
 func (r *inmemRepository) GetRideFareByID(ctx context.Context, id string) (*domain.RideFareModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fare, ok := r.rideFares[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyRideFare(fare), nil
+}
+
+func (r *inmemRepository) ConsumeRideFare(ctx context.Context, id string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	fare, ok := r.rideFares[id]
 	if !ok {
-		return nil, fmt.Errorf("ride fare not found")
+		return domain.ErrFareNotFound
+	}
+
+	if err := fare.Validate(now); err != nil {
+		return err
+	}
+
+	fare.ConsumedAt = &now
+	return nil
+}
+
+func (r *inmemRepository) DeleteExpiredRideFares(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, fare := range r.rideFares {
+		if fare.ExpiresAt.Before(before) {
+			delete(r.rideFares, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *inmemRepository) ListTripsByUser(ctx context.Context, userID string, offset, limit int) ([]*domain.TripModel, error) {
+	return r.listTrips(func(t *domain.TripModel) bool { return t.UserID == userID }, offset, limit), nil
+}
+
+func (r *inmemRepository) ListTripsByDriver(ctx context.Context, driverID string, offset, limit int) ([]*domain.TripModel, error) {
+	return r.listTrips(func(t *domain.TripModel) bool { return t.Driver.GetId() == driverID }, offset, limit), nil
+}
+
+func (r *inmemRepository) listTrips(match func(*domain.TripModel) bool, offset, limit int) []*domain.TripModel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var trips []*domain.TripModel
+	for _, trip := range r.trips {
+		if match(trip) {
+			trips = append(trips, copyTrip(trip))
+		}
+	}
+
+	// newest first, ties broken by ID so that pages are stable
+	sort.Slice(trips, func(i, j int) bool {
+		if !trips[i].CreatedAt.Equal(trips[j].CreatedAt) {
+			return trips[i].CreatedAt.After(trips[j].CreatedAt)
+		}
+		return trips[i].ID.Hex() > trips[j].ID.Hex()
+	})
+
+	if offset >= len(trips) {
+		return nil
+	}
+
+	trips = trips[offset:]
+	if len(trips) > limit {
+		trips = trips[:limit]
+	}
+
+	return trips
+}
+
+func (r *inmemRepository) CountRequestedTrips(ctx context.Context, cell string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, trip := range r.trips {
+		if trip.Status == domain.TripStatusRequested && strings.HasPrefix(trip.PickupGeohash, cell) {
+			counts[trip.RideFare.PackageSlug]++
+		}
 	}
-	return fare, nil
+	return counts, nil
 }
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ride-sharing/services/trip-service/internal/domain"
+	pbd "ride-sharing/shared/proto/driver"
+	pb "ride-sharing/shared/proto/trip"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newRequestedTrip(t *testing.T, repo *inmemRepository) string {
+	t.Helper()
+
+	trip := &domain.TripModel{
+		ID:       primitive.NewObjectID(),
+		UserID:   "rider-1",
+		Status:   domain.TripStatusRequested,
+		RideFare: &domain.RideFareModel{PackageSlug: "sedan"},
+		Driver:   &pb.TripDriver{},
+	}
+	if _, err := repo.CreateTrip(context.Background(), trip); err != nil {
+		t.Fatalf("CreateTrip() = %v", err)
+	}
+
+	return trip.ID.Hex()
+}
+
+func TestUpdateTripChecksTheCurrentStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInmemRepository()
+	tripID := newRequestedTrip(t, repo)
+
+	first := &pbd.Driver{Id: "driver-1"}
+	if err := repo.UpdateTrip(ctx, tripID, domain.TripStatusRequested, domain.TripStatusDriverAssigned, first); err != nil {
+		t.Fatalf("first UpdateTrip() = %v", err)
+	}
+
+	// a second accept read the trip before the first one was stored
+	second := &pbd.Driver{Id: "driver-2"}
+	err := repo.UpdateTrip(ctx, tripID, domain.TripStatusRequested, domain.TripStatusDriverAssigned, second)
+
+	var transitionErr *domain.InvalidTransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.From != domain.TripStatusDriverAssigned {
+		t.Fatalf("second UpdateTrip() = %v, want a transition error from %q", err, domain.TripStatusDriverAssigned)
+	}
+
+	trip, err := repo.GetTripByID(ctx, tripID)
+	if err != nil {
+		t.Fatalf("GetTripByID() = %v", err)
+	}
+	if trip.Driver.GetId() != "driver-1" {
+		t.Errorf("trip driver = %q, want driver-1", trip.Driver.GetId())
+	}
+}
+
+func TestUpdateTripNotFound(t *testing.T) {
+	repo := NewInmemRepository()
+
+	err := repo.UpdateTrip(context.Background(), primitive.NewObjectID().Hex(), domain.TripStatusRequested, domain.TripStatusCancelled, nil)
+	if !errors.Is(err, domain.ErrTripNotFound) {
+		t.Errorf("UpdateTrip() = %v, want ErrTripNotFound", err)
+	}
+}
+
+func TestGetTripByIDReturnsACopy(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInmemRepository()
+	tripID := newRequestedTrip(t, repo)
+
+	trip, err := repo.GetTripByID(ctx, tripID)
+	if err != nil {
+		t.Fatalf("GetTripByID() = %v", err)
+	}
+	trip.Status = domain.TripStatusCancelled
+	trip.RideFare.PackageSlug = "luxury"
+
+	stored, err := repo.GetTripByID(ctx, tripID)
+	if err != nil {
+		t.Fatalf("GetTripByID() = %v", err)
+	}
+	if stored.Status != domain.TripStatusRequested || stored.RideFare.PackageSlug != "sedan" {
+		t.Errorf("changing a returned trip changed the stored one: %+v", stored)
+	}
+}
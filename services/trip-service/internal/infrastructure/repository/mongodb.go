@@ -2,7 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"regexp"
+	"sync/atomic"
+	"time"
 
 	"ride-sharing/services/trip-service/internal/domain"
 	"ride-sharing/shared/db"
@@ -11,35 +16,46 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type mongoRepository struct {
 	db *mongo.Database
+	// cleared once MongoDB turns out to run standalone, which has no transactions
+	transactions atomic.Bool
 }
 
 func NewMongoRepository(db *mongo.Database) *mongoRepository {
-	return &mongoRepository{db: db}
+	r := &mongoRepository{db: db}
+	r.transactions.Store(true)
+	return r
 }
 
-func (r *mongoRepository) CreateTrip(ctx context.Context, trip *domain.TripModel) (*domain.TripModel, error) {
-	result, err := r.db.Collection(db.TripsCollection).InsertOne(ctx, trip)
+// CreateTrip keeps the ID given by the service, which the outbox events already carry.
+func (r *mongoRepository) CreateTrip(ctx context.Context, trip *domain.TripModel, events ...*domain.OutboxEvent) (*domain.TripModel, error) {
+	err := r.withOutbox(ctx, events, func(ctx context.Context) error {
+		_, err := r.db.Collection(db.TripsCollection).InsertOne(ctx, trip)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	trip.ID = result.InsertedID.(primitive.ObjectID)
-
 	return trip, nil
 }
 
 func (r *mongoRepository) GetTripByID(ctx context.Context, id string) (*domain.TripModel, error) {
+	// no trip has an ID that is not an ObjectID
 	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, err
+		return nil, nil
 	}
 
 	result := r.db.Collection(db.TripsCollection).FindOne(ctx, bson.M{"_id": _id})
 	if result.Err() != nil {
+		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+			return nil, nil
+		}
 		return nil, result.Err()
 	}
 
@@ -52,39 +68,156 @@ func (r *mongoRepository) GetTripByID(ctx context.Context, id string) (*domain.T
 	return &trip, nil
 }
 
-func (r *mongoRepository) UpdateTrip(ctx context.Context, tripID string, status string, driver *pbd.Driver) error {
+func (r *mongoRepository) UpdateTrip(ctx context.Context, tripID string, from, to domain.TripStatus, driver *pbd.Driver, events ...*domain.OutboxEvent) error {
+	return r.withOutbox(ctx, events, func(ctx context.Context) error {
+		return r.updateTrip(ctx, tripID, from, to, driver)
+	})
+}
+
+func (r *mongoRepository) updateTrip(ctx context.Context, tripID string, from, to domain.TripStatus, driver *pbd.Driver) error {
 	_id, err := primitive.ObjectIDFromHex(tripID)
 	if err != nil {
-		return err
+		return domain.ErrTripNotFound
 	}
 
-	update := bson.M{"$set": bson.M{"status": status}}
+	update := bson.M{"$set": bson.M{"status": to}}
 
 	if driver != nil {
 		update["$set"].(bson.M)["driver"] = driver
 	}
 
-	result, err := r.db.Collection(db.TripsCollection).UpdateOne(ctx, bson.M{"_id": _id}, update)
+	// the filter makes the status check and the update a single atomic operation
+	result, err := r.db.Collection(db.TripsCollection).UpdateOne(ctx, bson.M{"_id": _id, "status": from}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 1 {
+		return nil
+	}
+
+	// find out why the trip could not be updated
+	trip, err := r.GetTripByID(ctx, tripID)
 	if err != nil {
 		return err
 	}
 
-	if result.ModifiedCount == 0 {
-		return fmt.Errorf("trip not found: %s", tripID)
+	if trip == nil {
+		return domain.ErrTripNotFound
 	}
 
-	return nil
+	return &domain.InvalidTransitionError{
+		TripID: tripID,
+		From:   trip.Status,
+		To:     to,
+	}
 }
 
-func (r *mongoRepository) SaveRideFare(ctx context.Context, fare *domain.RideFareModel) error {
-	result, err := r.db.Collection(db.RideFaresCollection).InsertOne(ctx, fare)
-	if err != nil {
+// withOutbox runs fn and inserts the outbox events in one transaction.
+//
+// Transactions need MongoDB to run as a replica set, a single node one is enough. On a
+// standalone server, like a bare development container, the events are inserted after
+// fn instead: they are lost if the service stops in between.
+func (r *mongoRepository) withOutbox(ctx context.Context, events []*domain.OutboxEvent, fn func(ctx context.Context) error) error {
+	if len(events) == 0 {
+		return fn(ctx)
+	}
+
+	if r.transactions.Load() {
+		err := r.inTransaction(ctx, events, fn)
+		if !isTransactionNotSupported(err) {
+			return err
+		}
+
+		r.transactions.Store(false)
+		log.Printf("WARNING: MongoDB does not support transactions, run it as a replica set. Outbox events are no longer stored atomically with their trip")
+	}
+
+	if err := fn(ctx); err != nil {
 		return err
 	}
 
-	fare.ID = result.InsertedID.(primitive.ObjectID)
+	return r.insertOutboxEvents(ctx, events)
+}
+
+// illegalOperationCode is returned by a standalone server to commands run in a transaction
+const illegalOperationCode = 20
+
+// isTransactionNotSupported reports whether the transaction failed before writing
+// anything because the server does not support transactions.
+func isTransactionNotSupported(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) &&
+		serverErr.HasErrorCode(illegalOperationCode) &&
+		serverErr.HasErrorMessage("Transaction numbers are only allowed")
+}
+
+func (r *mongoRepository) inTransaction(ctx context.Context, events []*domain.OutboxEvent, fn func(ctx context.Context) error) error {
+	session, err := r.db.Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if err := fn(sc); err != nil {
+			return nil, err
+		}
+
+		return nil, r.insertOutboxEvents(sc, events)
+	})
+
+	return err
+}
+
+func (r *mongoRepository) insertOutboxEvents(ctx context.Context, events []*domain.OutboxEvent) error {
+	docs := make([]interface{}, len(events))
+	for i, event := range events {
+		docs[i] = event
+	}
+
+	_, err := r.db.Collection(db.OutboxCollection).InsertMany(ctx, docs)
+	return err
+}
+
+func (r *mongoRepository) SaveOutboxEvents(ctx context.Context, events ...*domain.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
 
-	return nil
+	return r.insertOutboxEvents(ctx, events)
+}
+
+func (r *mongoRepository) PendingOutboxEvents(ctx context.Context, limit int) ([]*domain.OutboxEvent, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.db.Collection(db.OutboxCollection).Find(ctx, bson.M{"publishedAt": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []*domain.OutboxEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *mongoRepository) MarkOutboxEventPublished(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	_, err := r.db.Collection(db.OutboxCollection).UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"publishedAt": at}},
+	)
+	return err
+}
+
+func (r *mongoRepository) SaveRideFare(ctx context.Context, fare *domain.RideFareModel) error {
+	_, err := r.db.Collection(db.RideFaresCollection).InsertOne(ctx, fare)
+	return err
 }
 
 func (r *mongoRepository) GetRideFareByID(ctx context.Context, id string) (*domain.RideFareModel, error) {
@@ -95,6 +228,9 @@ func (r *mongoRepository) GetRideFareByID(ctx context.Context, id string) (*doma
 
 	result := r.db.Collection(db.RideFaresCollection).FindOne(ctx, bson.M{"_id": _id})
 	if result.Err() != nil {
+		if errors.Is(result.Err(), mongo.ErrNoDocuments) {
+			return nil, nil
+		}
 		return nil, result.Err()
 	}
 
@@ -106,3 +242,113 @@ func (r *mongoRepository) GetRideFareByID(ctx context.Context, id string) (*doma
 
 	return &fare, nil
 }
+
+func (r *mongoRepository) ConsumeRideFare(ctx context.Context, id string, now time.Time) error {
+	_id, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	// the filter makes the check and the update a single atomic operation
+	result, err := r.db.Collection(db.RideFaresCollection).UpdateOne(ctx, bson.M{
+		"_id":        _id,
+		"consumedAt": nil,
+		"expiresAt":  bson.M{"$gt": now},
+	}, bson.M{"$set": bson.M{"consumedAt": now}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 1 {
+		return nil
+	}
+
+	// find out why the fare could not be used
+	fare, err := r.GetRideFareByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if fare == nil {
+		return domain.ErrFareNotFound
+	}
+
+	if err := fare.Validate(now); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("failed to consume ride fare %s", id)
+}
+
+func (r *mongoRepository) DeleteExpiredRideFares(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Collection(db.RideFaresCollection).DeleteMany(ctx, bson.M{
+		"expiresAt": bson.M{"$lt": before},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+func (r *mongoRepository) ListTripsByUser(ctx context.Context, userID string, offset, limit int) ([]*domain.TripModel, error) {
+	return r.listTrips(ctx, bson.M{"user_id": userID}, offset, limit)
+}
+
+func (r *mongoRepository) ListTripsByDriver(ctx context.Context, driverID string, offset, limit int) ([]*domain.TripModel, error) {
+	return r.listTrips(ctx, bson.M{"driver.id": driverID}, offset, limit)
+}
+
+func (r *mongoRepository) listTrips(ctx context.Context, filter bson.M, offset, limit int) ([]*domain.TripModel, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.db.Collection(db.TripsCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var trips []*domain.TripModel
+	if err := cursor.All(ctx, &trips); err != nil {
+		return nil, err
+	}
+
+	return trips, nil
+}
+
+func (r *mongoRepository) CountRequestedTrips(ctx context.Context, cell string) (map[string]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"status":        domain.TripStatusRequested,
+			"pickupGeohash": bson.M{"$regex": "^" + regexp.QuoteMeta(cell)},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$rideFare.packageSlug",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := r.db.Collection(db.TripsCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		PackageSlug string `bson:"_id"`
+		Count       int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		counts[result.PackageSlug] = result.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,42 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"log"
+	"ride-sharing/services/trip-service/internal/domain"
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+	"ride-sharing/shared/types"
+)
+
+type fallbackProvider struct {
+	providers []domain.RouteProvider
+}
+
+// NewFallbackProvider returns a RouteProvider that asks the given providers in order
+// and returns the first route found.
+func NewFallbackProvider(providers ...domain.RouteProvider) *fallbackProvider {
+	return &fallbackProvider{
+		providers: providers,
+	}
+}
+
+func (p *fallbackProvider) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
+	var errs []error
+
+	for _, provider := range p.providers {
+		route, err := provider.GetRoute(ctx, pickup, destination)
+		if err == nil {
+			return route, nil
+		}
+
+		log.Printf("route provider %T failed, trying the next one: %v", provider, err)
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil, domain.ErrNoRoute
+	}
+
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,43 @@
+package routing
+
+import (
+	"context"
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+	"ride-sharing/shared/types"
+	"ride-sharing/shared/util"
+)
+
+const DefaultAverageSpeedKmh = 30
+
+type haversineProvider struct {
+	metersPerSecond float64
+}
+
+// NewHaversineProvider returns a RouteProvider that needs no network: the route is the
+// straight line between the two points, travelled at the given average speed.
+func NewHaversineProvider(speedKmh float64) *haversineProvider {
+	if speedKmh <= 0 {
+		speedKmh = DefaultAverageSpeedKmh
+	}
+
+	return &haversineProvider{
+		metersPerSecond: speedKmh * 1000 / 3600,
+	}
+}
+
+func (p *haversineProvider) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
+	distance := util.HaversineDistance(pickup.Latitude, pickup.Longitude, destination.Latitude, destination.Longitude)
+
+	route := tripTypes.Route{
+		Distance: distance,
+		Duration: distance / p.metersPerSecond,
+	}
+	route.Geometry.Coordinates = [][]float64{
+		{pickup.Latitude, pickup.Longitude},
+		{destination.Latitude, destination.Longitude},
+	}
+
+	return &tripTypes.OsrmApiResponse{
+		Routes: []tripTypes.Route{route},
+	}, nil
+}
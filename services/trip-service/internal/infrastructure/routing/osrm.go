@@ -0,0 +1,60 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ride-sharing/services/trip-service/internal/domain"
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+	"ride-sharing/shared/types"
+	"time"
+)
+
+const DefaultOsrmBaseURL = "http://router.project-osrm.org"
+
+type osrmProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewOsrmProvider returns a RouteProvider backed by the OSRM HTTP API at baseURL.
+// Every request is bounded by timeout on top of the caller's context.
+func NewOsrmProvider(baseURL string, timeout time.Duration) *osrmProvider {
+	return &osrmProvider{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *osrmProvider) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
+	url := fmt.Sprintf("%s/route/v1/driving/%f,%f;%f,%f?overview=full&geometries=geojson", p.baseURL, pickup.Latitude, pickup.Longitude, destination.Latitude, destination.Longitude)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OSRM request: %w", err)
+	}
+
+	response, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from OSRM API: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OSRM API responded with status %d", response.StatusCode)
+	}
+
+	var route tripTypes.OsrmApiResponse
+
+	if err := json.NewDecoder(response.Body).Decode(&route); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if len(route.Routes) == 0 {
+		return nil, domain.ErrNoRoute
+	}
+
+	return &route, nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"log"
+	"ride-sharing/services/trip-service/internal/domain"
+	"time"
+)
+
+type fareSweeper struct {
+	repo     domain.TripRepository
+	interval time.Duration
+}
+
+// NewFareSweeper returns a sweeper that purges expired fare quotes every interval.
+func NewFareSweeper(repo domain.TripRepository, interval time.Duration) *fareSweeper {
+	return &fareSweeper{
+		repo:     repo,
+		interval: interval,
+	}
+}
+
+// Run purges expired fares until the context is cancelled.
+func (s *fareSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := s.repo.DeleteExpiredRideFares(ctx, now)
+			if err != nil {
+				log.Printf("failed to purge expired ride fares: %v", err)
+				continue
+			}
+
+			if deleted > 0 {
+				log.Printf("Purged %d expired ride fares", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"math"
+	"ride-sharing/services/trip-service/internal/domain"
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+)
+
+// pricingEngine prices a route for every configured car package.
+type pricingEngine struct {
+	rateCards []tripTypes.RateCard
+}
+
+func NewPricingEngine(cfg *tripTypes.PricingConfig) *pricingEngine {
+	return &pricingEngine{
+		rateCards: cfg.RateCards,
+	}
+}
+
+// Estimate returns one unsaved fare per package for the route, in the order of the rate cards.
+// Packages missing from surge are priced without surge.
+func (e *pricingEngine) Estimate(route *tripTypes.OsrmApiResponse, surge map[string]float64) []*domain.RideFareModel {
+	var distanceMeters, durationSeconds float64
+	if len(route.Routes) > 0 {
+		distanceMeters = route.Routes[0].Distance
+		durationSeconds = route.Routes[0].Duration
+	}
+
+	fares := make([]*domain.RideFareModel, 0, len(e.rateCards))
+	for _, card := range e.rateCards {
+		multiplier := surge[card.PackageSlug]
+		if multiplier < 1 {
+			multiplier = 1
+		}
+
+		breakdown := priceRoute(card, distanceMeters, durationSeconds, multiplier)
+
+		fares = append(fares, &domain.RideFareModel{
+			PackageSlug:       card.PackageSlug,
+			TotalPriceInCents: breakdown.Total(),
+			Currency:          card.Currency,
+			Breakdown:         breakdown,
+			SurgeMultiplier:   multiplier,
+			// quoted with the fare, so that it is in the fare currency
+			CancellationFeeInCents: card.CancellationFee,
+		})
+	}
+
+	return fares
+}
+
+// priceRoute applies a rate card to a route distance in meters and duration in seconds.
+// Surge multiplies the ride itself, never the booking fee.
+func priceRoute(card tripTypes.RateCard, distanceMeters, durationSeconds, surge float64) *domain.FareBreakdown {
+	breakdown := &domain.FareBreakdown{
+		BaseFare:     card.BaseFare,
+		DistanceFare: int64(math.Round(distanceMeters / 1000 * float64(card.PerKm))),
+		TimeFare:     int64(math.Round(durationSeconds / 60 * float64(card.PerMinute))),
+		BookingFee:   card.BookingFee,
+	}
+
+	// the booking fee is charged on top of the minimum fare
+	ride := breakdown.Total() - breakdown.BookingFee
+	if ride < card.MinimumFare {
+		breakdown.MinimumFareAdjustment = card.MinimumFare - ride
+		ride = card.MinimumFare
+	}
+
+	breakdown.SurgeFare = int64(math.Round(float64(ride) * (surge - 1)))
+
+	return breakdown
+}
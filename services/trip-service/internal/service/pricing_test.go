@@ -0,0 +1,103 @@
+package service
+
+import (
+	"testing"
+
+	"ride-sharing/services/trip-service/internal/domain"
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+)
+
+var sedan = tripTypes.RateCard{
+	PackageSlug:     "sedan",
+	Currency:        "usd",
+	BaseFare:        350,
+	PerKm:           120,
+	PerMinute:       20,
+	MinimumFare:     700,
+	BookingFee:      100,
+	CancellationFee: 500,
+}
+
+func TestPriceRoute(t *testing.T) {
+	tests := []struct {
+		name            string
+		distanceMeters  float64
+		durationSeconds float64
+		surge           float64
+		want            domain.FareBreakdown
+		wantTotal       int64
+	}{
+		{
+			name:            "above the minimum fare",
+			distanceMeters:  10000,
+			durationSeconds: 1200,
+			surge:           1,
+			want:            domain.FareBreakdown{BaseFare: 350, DistanceFare: 1200, TimeFare: 400, BookingFee: 100},
+			wantTotal:       2050,
+		},
+		{
+			name:            "raised to the minimum fare",
+			distanceMeters:  1000,
+			durationSeconds: 60,
+			surge:           1,
+			want:            domain.FareBreakdown{BaseFare: 350, DistanceFare: 120, TimeFare: 20, BookingFee: 100, MinimumFareAdjustment: 210},
+			wantTotal:       800,
+		},
+		{
+			name:            "pro rata rates are rounded",
+			distanceMeters:  1504,
+			durationSeconds: 91,
+			surge:           1,
+			want:            domain.FareBreakdown{BaseFare: 350, DistanceFare: 180, TimeFare: 30, BookingFee: 100, MinimumFareAdjustment: 140},
+			wantTotal:       800,
+		},
+		{
+			name:            "surge leaves the booking fee out",
+			distanceMeters:  10000,
+			durationSeconds: 1200,
+			surge:           1.5,
+			want:            domain.FareBreakdown{BaseFare: 350, DistanceFare: 1200, TimeFare: 400, BookingFee: 100, SurgeFare: 975},
+			wantTotal:       3025,
+		},
+		{
+			name:            "surge applies on top of the minimum fare",
+			distanceMeters:  0,
+			durationSeconds: 0,
+			surge:           2,
+			want:            domain.FareBreakdown{BaseFare: 350, BookingFee: 100, MinimumFareAdjustment: 350, SurgeFare: 700},
+			wantTotal:       1500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := priceRoute(sedan, tt.distanceMeters, tt.durationSeconds, tt.surge)
+			if *got != tt.want {
+				t.Errorf("priceRoute() = %+v, want %+v", *got, tt.want)
+			}
+			if got.Total() != tt.wantTotal {
+				t.Errorf("Total() = %d, want %d", got.Total(), tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	yen := tripTypes.RateCard{PackageSlug: "van", Currency: "jpy", BaseFare: 500, MinimumFare: 800, CancellationFee: 700}
+	engine := NewPricingEngine(&tripTypes.PricingConfig{RateCards: []tripTypes.RateCard{sedan, yen}})
+
+	route := &tripTypes.OsrmApiResponse{Routes: []tripTypes.Route{{Distance: 10000, Duration: 1200}}}
+
+	// a multiplier below 1 never lowers the price
+	fares := engine.Estimate(route, map[string]float64{"sedan": 0.5})
+	if len(fares) != 2 {
+		t.Fatalf("Estimate() returned %d fares, want 2", len(fares))
+	}
+
+	if fares[0].PackageSlug != "sedan" || fares[0].SurgeMultiplier != 1 || fares[0].TotalPriceInCents != 2050 {
+		t.Errorf("sedan fare = %+v", fares[0])
+	}
+	if fares[1].Currency != "jpy" || fares[1].TotalPriceInCents != 800 || fares[1].CancellationFeeInCents != 700 {
+		t.Errorf("van fare = %+v, want 800 jpy with a 700 jpy cancellation fee", fares[1])
+	}
+}
@@ -2,40 +2,67 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"ride-sharing/services/trip-service/internal/domain"
 	tripTypes "ride-sharing/services/trip-service/pkg/types"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
 	pbd "ride-sharing/shared/proto/driver"
 	pb "ride-sharing/shared/proto/trip"
 	"ride-sharing/shared/types"
+	"strconv"
+	"time"
 
+	"github.com/mmcloughlin/geohash"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type service struct {
-	repo domain.TripRepository
+	repo    domain.TripRepository
+	routes  domain.RouteProvider
+	pricing *pricingEngine
+	surge   *surgeEngine
+	fares   *tripTypes.FareConfig
 }
 
-func NewTripService(r domain.TripRepository) *service {
+func NewTripService(r domain.TripRepository, routes domain.RouteProvider, pricing *pricingEngine, surge *surgeEngine, fares *tripTypes.FareConfig) *service {
 	return &service{
-		repo: r,
+		repo:    r,
+		routes:  routes,
+		pricing: pricing,
+		surge:   surge,
+		fares:   fares,
 	}
 }
 
+// CreateTrip starts a trip from the fare, using the fare up so that it cannot start another one.
 func (s *service) CreateTrip(ctx context.Context, fare *domain.RideFareModel) (*domain.TripModel, error) {
+	if err := s.repo.ConsumeRideFare(ctx, fare.ID.Hex(), time.Now()); err != nil {
+		return nil, err
+	}
 
 	trip := domain.TripModel{
-		ID:       primitive.NewObjectID(),
-		UserID:   fare.UserID,
-		Status:   "pending",
-		RideFare: fare,
-		Driver:   &pb.TripDriver{},
+		ID:        primitive.NewObjectID(),
+		UserID:    fare.UserID,
+		Status:    domain.TripStatusRequested,
+		RideFare:  fare,
+		Driver:    &pb.TripDriver{},
+		CreatedAt: time.Now(),
+	}
+
+	if pickup, ok := fare.Pickup(); ok {
+		trip.PickupGeohash = geohash.Encode(pickup.Latitude, pickup.Longitude)
+	}
+
+	created, err := domain.NewOutboxEvent(contracts.TripEventCreated, trip.UserID, messaging.TripEventData{
+		Trip: trip.ToProto(),
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.CreateTrip(ctx, &trip)
+	return s.repo.CreateTrip(ctx, &trip, created)
 }
 
 func (s *service) GetAndValidateFare(ctx context.Context, fareID, userID string) (*domain.RideFareModel, error) {
@@ -45,130 +72,262 @@ func (s *service) GetAndValidateFare(ctx context.Context, fareID, userID string)
 	}
 
 	if fare == nil {
-		return nil, fmt.Errorf("ride fare not found")
+		return nil, domain.ErrFareNotFound
 	}
 
 	if fare.UserID != userID {
-		return nil, fmt.Errorf("fare does not belong to user")
+		return nil, domain.ErrFareNotOwned
+	}
+
+	if err := fare.Validate(time.Now()); err != nil {
+		return nil, err
 	}
 
 	return fare, nil
 }
 
 func (s *service) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
-	const baseUrl = "http://router.project-osrm.org"
+	return s.routes.GetRoute(ctx, pickup, destination)
+}
 
-	url := fmt.Sprintf("%s/route/v1/driving/%f,%f;%f,%f?overview=full&geometries=geojson", baseUrl, pickup.Latitude, pickup.Longitude, destination.Latitude, destination.Longitude)
+func (s *service) EstimatePackagesPriceWithRoute(ctx context.Context, pickup *types.Coordinate, route *tripTypes.OsrmApiResponse) []*domain.RideFareModel {
+	return s.pricing.Estimate(route, s.surge.Multipliers(ctx, pickup))
+}
 
-	response, err := http.Get(url)
+func (s *service) GenerateTripFares(ctx context.Context, f []*domain.RideFareModel, userID string, route *tripTypes.OsrmApiResponse) ([]*domain.RideFareModel, error) {
+	expiresAt := time.Now().Add(s.fares.TTL)
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch from OSRM API: %w", err)
+	for _, fare := range f {
+		fare.ID = primitive.NewObjectID()
+		fare.UserID = userID
+		fare.Route = route
+		fare.ExpiresAt = expiresAt
+
+		if err := s.repo.SaveRideFare(ctx, fare); err != nil {
+			return nil, fmt.Errorf("failed to save ride fare: %w", err)
+		}
 	}
 
-	defer response.Body.Close()
+	return f, nil
+}
 
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+func (s *service) GetTripByID(ctx context.Context, id string) (*domain.TripModel, error) {
+	return s.repo.GetTripByID(ctx, id)
+}
+
+const (
+	defaultTripsPageSize = 20
+	maxTripsPageSize     = 100
+)
+
+func (s *service) ListTripsByUser(ctx context.Context, userID string, pageSize int, pageToken string) (*domain.TripPage, error) {
+	return listTripsPage(pageSize, pageToken, func(offset, limit int) ([]*domain.TripModel, error) {
+		return s.repo.ListTripsByUser(ctx, userID, offset, limit)
+	})
+}
+
+func (s *service) ListTripsByDriver(ctx context.Context, driverID string, pageSize int, pageToken string) (*domain.TripPage, error) {
+	return listTripsPage(pageSize, pageToken, func(offset, limit int) ([]*domain.TripModel, error) {
+		return s.repo.ListTripsByDriver(ctx, driverID, offset, limit)
+	})
+}
+
+// listTripsPage turns a page token, which is the offset of the page, into a repository
+// query. One extra trip is fetched to know whether another page follows.
+func listTripsPage(pageSize int, pageToken string, list func(offset, limit int) ([]*domain.TripModel, error)) (*domain.TripPage, error) {
+	if pageSize <= 0 {
+		pageSize = defaultTripsPageSize
+	}
+	if pageSize > maxTripsPageSize {
+		pageSize = maxTripsPageSize
 	}
 
-	var route tripTypes.OsrmApiResponse
+	offset := 0
+	if pageToken != "" {
+		var err error
+		offset, err = strconv.Atoi(pageToken)
+		if err != nil || offset < 0 {
+			return nil, domain.ErrInvalidPageToken
+		}
+	}
 
-	if err := json.Unmarshal(body, &route); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	trips, err := list(offset, pageSize+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trips: %w", err)
 	}
 
-	if len(route.Routes) == 0 {
-		return &tripTypes.OsrmApiResponse{
-			Routes: []tripTypes.Route{
-				{
-					Distance: 10000,
-					Duration: 40,
-					Geometry: struct {
-						Coordinates [][]float64 "json:\"coordinates\""
-					}{
-						Coordinates: [][]float64{
-							{pickup.Latitude, pickup.Longitude},
-							{destination.Latitude, destination.Longitude},
-						},
-					},
-				},
-			},
-		}, nil
+	page := &domain.TripPage{Trips: trips}
+	if len(trips) > pageSize {
+		page.Trips = trips[:pageSize]
+		page.NextPageToken = strconv.Itoa(offset + pageSize)
 	}
 
-	return &route, nil
+	return page, nil
 }
 
-func (s *service) EstimatePackagesPriceWithRoute(route *tripTypes.OsrmApiResponse) []*domain.RideFareModel {
-	baseFares := getBaseFares()
+// UpdateTrip moves the trip to the given status, rejecting transitions that the
+// trip lifecycle does not allow with an InvalidTransitionError.
+func (s *service) UpdateTrip(ctx context.Context, tripID string, status domain.TripStatus, driver *pbd.Driver) error {
+	trip, err := s.repo.GetTripByID(ctx, tripID)
+	if err != nil {
+		return fmt.Errorf("failed to get trip: %w", err)
+	}
 
-	estimatedFares := make([]*domain.RideFareModel, len(baseFares))
+	if trip == nil {
+		return domain.ErrTripNotFound
+	}
 
-	for i, fare := range baseFares {
-		estimatedFares[i] = estimateFareRoute(fare, route)
+	if err := trip.ValidateTransition(status); err != nil {
+		return err
 	}
 
-	return estimatedFares
+	return s.repo.UpdateTrip(ctx, tripID, trip.Status, status, driver)
 }
 
-func estimateFareRoute(f *domain.RideFareModel, r *tripTypes.OsrmApiResponse) *domain.RideFareModel {
-	pricingConfig := tripTypes.DefaultPricingConfig()
+// AssignDriver gives the trip to the driver, announcing the assignment to the rider
+// and asking payment-service for a checkout session in the same repository write.
+func (s *service) AssignDriver(ctx context.Context, tripID string, driver *pbd.Driver) (*domain.TripModel, error) {
+	trip, err := s.repo.GetTripByID(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip: %w", err)
+	}
 
-	carPackagePrice := f.TotalPriceInCents
+	if trip == nil {
+		return nil, domain.ErrTripNotFound
+	}
 
-	route := r.Routes[0]
-	distanceKm := route.Distance
-	durationInMinutes := route.Duration
+	// a second accept must never replace the driver that already got the trip
+	if trip.Status != domain.TripStatusRequested || trip.Driver.GetId() != "" {
+		return nil, domain.ErrTripAlreadyTaken
+	}
 
-	distanceFare := distanceKm * pricingConfig.PricePerUnitOfDistance
-	timeFare := durationInMinutes * pricingConfig.PricingPerMinute
-	totalPrice := distanceFare + timeFare + carPackagePrice
+	if err := trip.ValidateTransition(domain.TripStatusDriverAssigned); err != nil {
+		return nil, err
+	}
 
-	return &domain.RideFareModel{
-		TotalPriceInCents: totalPrice,
-		PackageSlug:       f.PackageSlug,
+	assigned := *trip
+	assigned.Status = domain.TripStatusDriverAssigned
+	assigned.Driver = &pb.TripDriver{
+		Id:             driver.Id,
+		Name:           driver.Name,
+		CarPlate:       driver.CarPlate,
+		ProfilePicture: driver.ProfilePicture,
 	}
-}
-func (s *service) GenerateTripFares(ctx context.Context, f []*domain.RideFareModel, userID string, route *tripTypes.OsrmApiResponse) ([]*domain.RideFareModel, error) {
-	for _, fare := range f {
-		fare.ID = primitive.NewObjectID()
-		fare.UserID = userID
-		fare.Route = route
 
-		if err := s.repo.SaveRideFare(ctx, fare); err != nil {
-			return nil, fmt.Errorf("failed to save ride fare: %w", err)
+	assignedEvent, err := domain.NewOutboxEvent(contracts.TripEventDriverAssigned, trip.UserID, messaging.TripEventData{
+		Trip: assigned.ToProto(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paymentEvent, err := domain.NewOutboxEvent(contracts.PaymentCmdCreateSession, trip.UserID, messaging.PaymentTripResponseData{
+		TripID:   tripID,
+		UserID:   trip.UserID,
+		DriverID: driver.Id,
+		Amount:   trip.RideFare.TotalPriceInCents,
+		Currency: trip.RideFare.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTrip(ctx, tripID, trip.Status, domain.TripStatusDriverAssigned, driver, assignedEvent, paymentEvent); err != nil {
+		// another driver was assigned, or the trip cancelled, since it was read
+		if errors.Is(err, domain.ErrInvalidTripTransition) {
+			return nil, domain.ErrTripAlreadyTaken
 		}
+		return nil, fmt.Errorf("failed to assign driver: %w", err)
 	}
 
-	return f, nil
+	return &assigned, nil
 }
 
-func getBaseFares() []*domain.RideFareModel {
-	return []*domain.RideFareModel{
-		{
-			PackageSlug:       "suv",
-			TotalPriceInCents: 200,
-		},
-		{
-			PackageSlug:       "sedan",
-			TotalPriceInCents: 350,
-		},
-		{
-			PackageSlug:       "van",
-			TotalPriceInCents: 400,
-		},
-		{
-			PackageSlug:       "luxury",
-			TotalPriceInCents: 1000,
-		},
+// DeclineTrip records that the driver turned the trip down so that dispatch moves on.
+// Declines arriving after the trip left the requested state are ignored.
+func (s *service) DeclineTrip(ctx context.Context, tripID, driverID string) error {
+	trip, err := s.repo.GetTripByID(ctx, tripID)
+	if err != nil {
+		return fmt.Errorf("failed to get trip: %w", err)
 	}
-}
 
-func (s *service) GetTripByID(ctx context.Context, id string) (*domain.TripModel, error) {
-	return s.repo.GetTripByID(ctx, id)
+	if trip == nil {
+		return domain.ErrTripNotFound
+	}
+
+	if trip.Status != domain.TripStatusRequested {
+		return domain.ErrTripAlreadyTaken
+	}
+
+	event, err := domain.NewOutboxEvent(contracts.TripEventDriverNotInterested, trip.UserID, messaging.TripDriverNotInterestedData{
+		Trip:     trip.ToProto(),
+		DriverID: driverID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SaveOutboxEvents(ctx, event)
 }
-func (s *service) UpdateTrip(ctx context.Context, tripID string, status string, driver *pbd.Driver) error {
-	return s.repo.UpdateTrip(ctx, tripID, status, driver)
+
+// CancelTrip cancels the trip on behalf of its rider or its assigned driver.
+// A rider cancelling while the assigned driver is on the way is charged the cancellation fee of the fare.
+func (s *service) CancelTrip(ctx context.Context, tripID, userID string) (*domain.TripCancellation, error) {
+	trip, err := s.repo.GetTripByID(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip: %w", err)
+	}
+
+	if trip == nil {
+		return nil, domain.ErrTripNotFound
+	}
+
+	var cancelledBy string
+	switch {
+	case userID == trip.UserID:
+		cancelledBy = messaging.CancelledByRider
+	case trip.Driver.GetId() != "" && userID == trip.Driver.GetId():
+		cancelledBy = messaging.CancelledByDriver
+	default:
+		return nil, domain.ErrNotTripParticipant
+	}
+
+	if err := trip.ValidateTransition(domain.TripStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	// the fee pays for a driver on the way, not for trips without one or whose payment failed
+	var fee int64
+	if cancelledBy == messaging.CancelledByRider {
+		switch trip.Status {
+		case domain.TripStatusDriverAssigned, domain.TripStatusDriverArriving:
+			fee = trip.RideFare.CancellationFeeInCents
+		}
+	}
+
+	cancelled := *trip
+	cancelled.Status = domain.TripStatusCancelled
+
+	event, err := domain.NewOutboxEvent(contracts.TripEventCancelled, trip.UserID, messaging.TripCancelledData{
+		Trip:                   cancelled.ToProto(),
+		CancelledBy:            cancelledBy,
+		CancellationFeeInCents: fee,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTrip(ctx, tripID, trip.Status, domain.TripStatusCancelled, nil, event); err != nil {
+		// the trip moved on since it was read, the fee may no longer apply
+		if errors.Is(err, domain.ErrInvalidTripTransition) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to cancel trip: %w", err)
+	}
+
+	return &domain.TripCancellation{
+		Trip:        &cancelled,
+		CancelledBy: cancelledBy,
+		FeeInCents:  fee,
+	}, nil
 }
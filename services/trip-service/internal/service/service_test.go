@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/services/trip-service/internal/infrastructure/repository"
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+	pb "ride-sharing/shared/proto/trip"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUpdateTripNotFound(t *testing.T) {
+	s := NewTripService(repository.NewInmemRepository(), nil, nil, nil, nil)
+
+	err := s.UpdateTrip(context.Background(), primitive.NewObjectID().Hex(), domain.TripStatusCancelled, nil)
+	if !errors.Is(err, domain.ErrTripNotFound) {
+		t.Errorf("UpdateTrip() = %v, want ErrTripNotFound", err)
+	}
+}
+
+func TestCancelTripFee(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      domain.TripStatus
+		cancelledBy string
+		wantFee     int64
+	}{
+		{"rider before a driver is assigned", domain.TripStatusRequested, "rider-1", 0},
+		{"rider once a driver is assigned", domain.TripStatusDriverAssigned, "rider-1", 500},
+		{"rider while the driver is arriving", domain.TripStatusDriverArriving, "rider-1", 500},
+		{"rider after the payment failed", domain.TripStatusPaymentFailed, "rider-1", 0},
+		{"driver", domain.TripStatusDriverAssigned, "driver-1", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repository.NewInmemRepository()
+			s := NewTripService(repo, nil, nil, nil, nil)
+
+			trip := &domain.TripModel{
+				ID:     primitive.NewObjectID(),
+				UserID: "rider-1",
+				Status: tt.status,
+				RideFare: &domain.RideFareModel{
+					PackageSlug:            "sedan",
+					CancellationFeeInCents: 500,
+					Route:                  &tripTypes.OsrmApiResponse{},
+				},
+				Driver: &pb.TripDriver{},
+			}
+			if tt.status != domain.TripStatusRequested {
+				trip.Driver.Id = "driver-1"
+			}
+			if _, err := repo.CreateTrip(ctx, trip); err != nil {
+				t.Fatalf("CreateTrip() = %v", err)
+			}
+
+			cancellation, err := s.CancelTrip(ctx, trip.ID.Hex(), tt.cancelledBy)
+			if err != nil {
+				t.Fatalf("CancelTrip() = %v", err)
+			}
+			if cancellation.FeeInCents != tt.wantFee {
+				t.Errorf("fee = %d, want %d", cancellation.FeeInCents, tt.wantFee)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"ride-sharing/services/trip-service/internal/domain"
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+	"ride-sharing/shared/types"
+	"sync"
+	"time"
+
+	"github.com/mmcloughlin/geohash"
+)
+
+// surgeEngine computes surge multipliers per geohash cell and package from the
+// trips waiting for a driver (demand) and the available drivers (supply).
+type surgeEngine struct {
+	repo   domain.TripRepository
+	supply domain.DriverSupply
+	config *tripTypes.SurgeConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	cells     map[string]*cellSurge
+	lastSweep time.Time
+}
+
+// cellSurge is the unrounded multiplier of every package of a cell that surges, or is
+// about to, as of the last quote in the cell.
+type cellSurge struct {
+	multipliers map[string]float64
+	updatedAt   time.Time
+}
+
+// staleHalfLives is how many half-lives a cell is remembered without quotes. Its last
+// measurement says nothing about the cell by then, the next quote starts it over.
+const staleHalfLives = 10
+
+func NewSurgeEngine(repo domain.TripRepository, supply domain.DriverSupply, config *tripTypes.SurgeConfig) *surgeEngine {
+	return &surgeEngine{
+		repo:   repo,
+		supply: supply,
+		config: config,
+		now:    time.Now,
+		cells:  make(map[string]*cellSurge),
+	}
+}
+
+// Multipliers returns the surge multiplier of every package around the pickup.
+// Packages without surge are left out, as are all packages when supply or demand
+// cannot be measured, so that pricing never fails because of surge.
+//
+// A multiplier moves towards the demand/supply ratio by how much time has passed since
+// the cell was last quoted, so that a burst of quotes moves it no faster than a single one.
+func (e *surgeEngine) Multipliers(ctx context.Context, pickup *types.Coordinate) map[string]float64 {
+	cell := geohash.EncodeWithPrecision(pickup.Latitude, pickup.Longitude, e.config.GeohashPrecision)
+
+	demand, err := e.repo.CountRequestedTrips(ctx, cell)
+	if err != nil {
+		log.Printf("failed to count requested trips in cell %s, pricing without surge: %v", cell, err)
+		return nil
+	}
+
+	supply, err := e.supply.AvailableDrivers(ctx, cell)
+	if err != nil {
+		log.Printf("failed to get driver supply in cell %s, pricing without surge: %v", cell, err)
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	e.sweep(now)
+
+	// a cell quoted for the first time starts from no surge
+	previous, ok := e.cells[cell]
+	if !ok {
+		previous = &cellSurge{updatedAt: now}
+	}
+
+	// weight of the latest ratio against the previous multiplier, 1 without smoothing
+	weight := 1.0
+	if e.config.HalfLife > 0 {
+		weight = 1 - math.Exp2(-float64(now.Sub(previous.updatedAt))/float64(e.config.HalfLife))
+	}
+
+	packages := make(map[string]bool)
+	for slug := range demand {
+		packages[slug] = true
+	}
+	// a cell that was surging keeps decaying even once demand is gone
+	for slug := range previous.multipliers {
+		packages[slug] = true
+	}
+
+	current := &cellSurge{multipliers: make(map[string]float64), updatedAt: now}
+	multipliers := make(map[string]float64)
+
+	for slug := range packages {
+		last, ok := previous.multipliers[slug]
+		if !ok {
+			last = 1
+		}
+
+		ratio := e.ratio(demand[slug], supply[slug])
+		multiplier := last + weight*(ratio-last)
+
+		// round to two decimals so riders see a stable value
+		if rounded := math.Round(multiplier*100) / 100; rounded > 1 {
+			multipliers[slug] = rounded
+		} else if ratio == 1 {
+			// settled back to no surge
+			continue
+		}
+
+		current.multipliers[slug] = multiplier
+	}
+
+	if len(current.multipliers) == 0 {
+		delete(e.cells, cell)
+	} else {
+		e.cells[cell] = current
+	}
+
+	if len(multipliers) == 0 {
+		return nil
+	}
+	return multipliers
+}
+
+// sweep forgets the cells that have not been quoted for staleHalfLives, at most once per
+// half-life. The caller must hold e.mu.
+func (e *surgeEngine) sweep(now time.Time) {
+	if now.Sub(e.lastSweep) < e.config.HalfLife {
+		return
+	}
+	e.lastSweep = now
+
+	for cell, surge := range e.cells {
+		if now.Sub(surge.updatedAt) > staleHalfLives*e.config.HalfLife {
+			delete(e.cells, cell)
+		}
+	}
+}
+
+// ratio turns demand and supply into a multiplier between 1 and MaxMultiplier.
+func (e *surgeEngine) ratio(demand, supply int) float64 {
+	if demand <= supply {
+		return 1
+	}
+
+	if supply == 0 {
+		return e.config.MaxMultiplier
+	}
+
+	return math.Min(float64(demand)/float64(supply), e.config.MaxMultiplier)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ride-sharing/services/trip-service/internal/domain"
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+	"ride-sharing/shared/types"
+)
+
+// demandRepository only answers CountRequestedTrips.
+type demandRepository struct {
+	domain.TripRepository
+	demand map[string]int
+	err    error
+}
+
+func (r *demandRepository) CountRequestedTrips(ctx context.Context, cell string) (map[string]int, error) {
+	return r.demand, r.err
+}
+
+type staticSupply struct {
+	supply map[string]int
+	err    error
+}
+
+func (s *staticSupply) AvailableDrivers(ctx context.Context, cell string) (map[string]int, error) {
+	return s.supply, s.err
+}
+
+var pickup = &types.Coordinate{Latitude: 52.52, Longitude: 13.405}
+
+func TestSurgeRatio(t *testing.T) {
+	engine := NewSurgeEngine(nil, nil, &tripTypes.SurgeConfig{MaxMultiplier: 3})
+
+	tests := []struct {
+		demand, supply int
+		want           float64
+	}{
+		{0, 0, 1},
+		{2, 2, 1},
+		{1, 4, 1},
+		{3, 2, 1.5},
+		{5, 0, 3},
+		{10, 1, 3},
+	}
+
+	for _, tt := range tests {
+		if got := engine.ratio(tt.demand, tt.supply); got != tt.want {
+			t.Errorf("ratio(%d, %d) = %v, want %v", tt.demand, tt.supply, got, tt.want)
+		}
+	}
+}
+
+// steppingClock is a clock that only moves when told to.
+type steppingClock struct {
+	now time.Time
+}
+
+func (c *steppingClock) Now() time.Time { return c.now }
+
+func newTestSurgeEngine(repo domain.TripRepository, supply domain.DriverSupply) (*surgeEngine, *steppingClock) {
+	clock := &steppingClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	engine := NewSurgeEngine(repo, supply, &tripTypes.SurgeConfig{GeohashPrecision: 5, MaxMultiplier: 3, HalfLife: time.Minute})
+	engine.now = clock.Now
+	return engine, clock
+}
+
+func TestSurgeMultipliersAreSmoothedOverTime(t *testing.T) {
+	repo := &demandRepository{}
+	supply := &staticSupply{supply: map[string]int{"sedan": 1, "van": 2}}
+	engine, clock := newTestSurgeEngine(repo, supply)
+
+	busy := map[string]int{"sedan": 4, "van": 1}
+	quiet := map[string]int{}
+
+	steps := []struct {
+		name    string
+		elapsed time.Duration
+		demand  map[string]int
+		want    float64 // sedan multiplier, 0 when it does not surge
+	}{
+		{"first quote of the cell", 0, busy, 0},
+		{"one half-life later", time.Minute, busy, 2},
+		{"repeated quote", time.Second, busy, 2.01},
+		{"repeated quote", 0, busy, 2.01},
+		{"repeated quote", 0, busy, 2.01},
+		{"demand is gone", time.Minute - time.Second, quiet, 1.51},
+		{"decaying", 2 * time.Minute, quiet, 1.13},
+		{"back to normal", 10 * time.Minute, quiet, 0},
+	}
+
+	for i, step := range steps {
+		clock.now = clock.now.Add(step.elapsed)
+		repo.demand = step.demand
+
+		multipliers := engine.Multipliers(context.Background(), pickup)
+		if got := multipliers["sedan"]; got != step.want {
+			t.Errorf("step %d, %s: sedan multiplier = %v, want %v", i, step.name, got, step.want)
+		}
+		if _, ok := multipliers["van"]; ok {
+			t.Errorf("step %d, %s: van surges with more drivers than trips", i, step.name)
+		}
+	}
+
+	if len(engine.cells) != 0 {
+		t.Errorf("the cell is still remembered once surge is over: %v", engine.cells)
+	}
+}
+
+func TestSurgeForgetsStaleCells(t *testing.T) {
+	repo := &demandRepository{demand: map[string]int{"sedan": 4}}
+	supply := &staticSupply{supply: map[string]int{"sedan": 1}}
+	engine, clock := newTestSurgeEngine(repo, supply)
+
+	elsewhere := &types.Coordinate{Latitude: 48.85, Longitude: 2.35}
+	engine.Multipliers(context.Background(), elsewhere)
+
+	clock.now = clock.now.Add(time.Minute)
+	engine.Multipliers(context.Background(), pickup)
+	if len(engine.cells) != 2 {
+		t.Fatalf("%d cells remembered, want 2", len(engine.cells))
+	}
+
+	// only the pickup cell keeps being quoted
+	for i := 0; i < staleHalfLives+1; i++ {
+		clock.now = clock.now.Add(time.Minute)
+		engine.Multipliers(context.Background(), pickup)
+	}
+
+	if len(engine.cells) != 1 {
+		t.Errorf("%d cells remembered, want only the one still quoted", len(engine.cells))
+	}
+}
+
+func TestSurgeWithoutMeasurements(t *testing.T) {
+	config := &tripTypes.SurgeConfig{GeohashPrecision: 5, MaxMultiplier: 3, HalfLife: time.Minute}
+
+	tests := []struct {
+		name   string
+		repo   *demandRepository
+		supply *staticSupply
+	}{
+		{"demand unknown", &demandRepository{err: errors.New("database down")}, &staticSupply{}},
+		{"supply unknown", &demandRepository{demand: map[string]int{"sedan": 4}}, &staticSupply{err: errors.New("driver-service down")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewSurgeEngine(tt.repo, tt.supply, config).Multipliers(context.Background(), pickup); got != nil {
+				t.Errorf("Multipliers() = %v, want no surge", got)
+			}
+		})
+	}
+}
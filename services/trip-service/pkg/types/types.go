@@ -1,6 +1,13 @@
 package types
 
-import tripGrpc "ride-sharing/shared/proto/trip"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tripGrpc "ride-sharing/shared/proto/trip"
+)
 
 type OsrmApiResponse struct {
 	Routes []Route `json:"routes"`
@@ -46,14 +53,95 @@ func (r *OsrmApiResponse) ToProto() *tripGrpc.Route {
 	}
 }
 
+// RateCard is the tariff of a car package. Currency is a lowercase ISO 4217 code and amounts
+// are in its minor units (cents for usd, yen for the zero-decimal jpy), per-km and per-minute
+// rates are charged pro rata. The cancellation fee is charged when a rider cancels while the
+// assigned driver is on the way.
+type RateCard struct {
+	PackageSlug     string `json:"packageSlug"`
+	Currency        string `json:"currency"`
+	BaseFare        int64  `json:"baseFare"`
+	PerKm           int64  `json:"perKm"`
+	PerMinute       int64  `json:"perMinute"`
+	MinimumFare     int64  `json:"minimumFare"`
+	BookingFee      int64  `json:"bookingFee"`
+	CancellationFee int64  `json:"cancellationFee"`
+}
+
 type PricingConfig struct {
-	PricePerUnitOfDistance float64
-	PricingPerMinute       float64
+	RateCards []RateCard `json:"rateCards"`
 }
 
 func DefaultPricingConfig() *PricingConfig {
 	return &PricingConfig{
-		PricePerUnitOfDistance: 1.5,
-		PricingPerMinute:       0.25, // Example value
+		RateCards: []RateCard{
+			{PackageSlug: "suv", Currency: "usd", BaseFare: 200, PerKm: 150, PerMinute: 25, MinimumFare: 600, BookingFee: 100, CancellationFee: 500},
+			{PackageSlug: "sedan", Currency: "usd", BaseFare: 350, PerKm: 120, PerMinute: 20, MinimumFare: 700, BookingFee: 100, CancellationFee: 500},
+			{PackageSlug: "van", Currency: "usd", BaseFare: 400, PerKm: 180, PerMinute: 30, MinimumFare: 900, BookingFee: 100, CancellationFee: 500},
+			{PackageSlug: "luxury", Currency: "usd", BaseFare: 1000, PerKm: 300, PerMinute: 50, MinimumFare: 2000, BookingFee: 150, CancellationFee: 500},
+		},
+	}
+}
+
+// LoadPricingConfig reads a JSON encoded PricingConfig.
+func LoadPricingConfig(data []byte) (*PricingConfig, error) {
+	var cfg PricingConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing config: %w", err)
+	}
+
+	if len(cfg.RateCards) == 0 {
+		return nil, fmt.Errorf("pricing config has no rate cards")
+	}
+
+	seen := make(map[string]bool, len(cfg.RateCards))
+	for i := range cfg.RateCards {
+		card := &cfg.RateCards[i]
+		card.Currency = strings.ToLower(card.Currency)
+
+		if card.PackageSlug == "" || len(card.Currency) != 3 {
+			return nil, fmt.Errorf("rate cards need a package slug and a three-letter currency code")
+		}
+		if seen[card.PackageSlug] {
+			return nil, fmt.Errorf("duplicate rate card for package %q", card.PackageSlug)
+		}
+		if card.BaseFare < 0 || card.PerKm < 0 || card.PerMinute < 0 || card.MinimumFare < 0 || card.BookingFee < 0 || card.CancellationFee < 0 {
+			return nil, fmt.Errorf("rate card for package %q has a negative amount", card.PackageSlug)
+		}
+		seen[card.PackageSlug] = true
+	}
+
+	return &cfg, nil
+}
+
+type SurgeConfig struct {
+	// GeohashPrecision sets the size of the cells supply and demand are compared in
+	GeohashPrecision uint
+	// MaxMultiplier caps the surge multiplier
+	MaxMultiplier float64
+	// HalfLife is how long the multiplier of a cell takes to move halfway from its previous
+	// value to the latest demand/supply ratio, however many quotes are requested meanwhile
+	HalfLife time.Duration
+}
+
+func DefaultSurgeConfig() *SurgeConfig {
+	return &SurgeConfig{
+		GeohashPrecision: 5,
+		MaxMultiplier:    3,
+		HalfLife:         time.Minute,
+	}
+}
+
+type FareConfig struct {
+	// TTL is how long a fare quote can be used to start a trip
+	TTL time.Duration
+	// SweepInterval is how often expired fares are purged
+	SweepInterval time.Duration
+}
+
+func DefaultFareConfig() *FareConfig {
+	return &FareConfig{
+		TTL:           5 * time.Minute,
+		SweepInterval: time.Minute,
 	}
 }
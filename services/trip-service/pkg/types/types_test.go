@@ -0,0 +1,35 @@
+package types
+
+import "testing"
+
+func TestLoadPricingConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{"valid", `{"rateCards":[{"packageSlug":"sedan","currency":"JPY","baseFare":500,"cancellationFee":700}]}`, false},
+		{"no rate cards", `{"rateCards":[]}`, true},
+		{"no currency", `{"rateCards":[{"packageSlug":"sedan"}]}`, true},
+		{"duplicate package", `{"rateCards":[{"packageSlug":"sedan","currency":"usd"},{"packageSlug":"sedan","currency":"eur"}]}`, true},
+		{"negative cancellation fee", `{"rateCards":[{"packageSlug":"sedan","currency":"usd","cancellationFee":-1}]}`, true},
+		{"not json", `rateCards`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadPricingConfig([]byte(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPricingConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			card := cfg.RateCards[0]
+			if card.Currency != "jpy" || card.CancellationFee != 700 {
+				t.Errorf("rate card = %+v, want the jpy currency and a 700 jpy cancellation fee", card)
+			}
+		})
+	}
+}
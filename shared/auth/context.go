@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC metadata keys carrying the identity to downstream services
+const (
+	UserIDMetadataKey = "x-user-id"
+	RoleMetadataKey   = "x-user-role"
+)
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the verified identity.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity stored by WithIdentity.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// FromIncomingContext returns the identity a gRPC client sent in the request metadata.
+// Services trust it because only the gateway, which verified the token, talks to them.
+func FromIncomingContext(ctx context.Context) (*Identity, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	userIDs, roles := md.Get(UserIDMetadataKey), md.Get(RoleMetadataKey)
+	if len(userIDs) == 0 || len(roles) == 0 || userIDs[0] == "" {
+		return nil, false
+	}
+
+	return &Identity{UserID: userIDs[0], Role: Role(roles[0])}, true
+}
+
+// IncomingUserID returns the ID of the user the gateway authenticated for the gRPC request.
+// The user ID of the request body, when set, must be the same: users only act for themselves.
+func IncomingUserID(ctx context.Context, requested string) (string, error) {
+	identity, ok := FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "the request has no authenticated user")
+	}
+
+	if requested != "" && requested != identity.UserID {
+		return "", status.Errorf(codes.PermissionDenied, "user %s cannot act for user %s", identity.UserID, requested)
+	}
+
+	return identity.UserID, nil
+}
+
+// DialOptions makes a gRPC client forward the identity of the context in the request metadata.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(unaryClientInterceptor),
+	}
+}
+
+func unaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if identity, ok := FromContext(ctx); ok {
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			UserIDMetadataKey, identity.UserID,
+			RoleMetadataKey, string(identity.Role),
+		)
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
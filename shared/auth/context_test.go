@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestIncomingUserID(t *testing.T) {
+	authenticated := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		UserIDMetadataKey, "driver-1",
+		RoleMetadataKey, string(RoleDriver),
+	))
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requested string
+		want      string
+		wantCode  codes.Code
+	}{
+		{"same user", authenticated, "driver-1", "driver-1", codes.OK},
+		{"no user in the request", authenticated, "", "driver-1", codes.OK},
+		{"another user", authenticated, "driver-2", "", codes.PermissionDenied},
+		{"not authenticated", context.Background(), "driver-1", "", codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IncomingUserID(tt.ctx, tt.requested)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("IncomingUserID() error = %v, want code %s", err, tt.wantCode)
+			}
+			if got != tt.want {
+				t.Errorf("IncomingUserID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
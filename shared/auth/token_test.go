@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenManager(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokens := NewTokenManager([]byte("secret"), time.Hour)
+
+	rider, _, err := tokens.Issue(Identity{UserID: "rider-1", Role: RoleRider}, now)
+	if err != nil {
+		t.Fatalf("Issue() = %v", err)
+	}
+
+	parts := strings.Split(rider, ".")
+	forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"rider-1","role":"driver","exp":9999999999}`)) + "." + parts[2]
+
+	otherSecret, _, err := NewTokenManager([]byte("other"), time.Hour).Issue(Identity{UserID: "rider-1", Role: RoleRider}, now)
+	if err != nil {
+		t.Fatalf("Issue() = %v", err)
+	}
+
+	noUser, _, err := tokens.Issue(Identity{Role: RoleRider}, now)
+	if err != nil {
+		t.Fatalf("Issue() = %v", err)
+	}
+
+	badRole, _, err := tokens.Issue(Identity{UserID: "admin-1", Role: "admin"}, now)
+	if err != nil {
+		t.Fatalf("Issue() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		at      time.Time
+		want    *Identity
+		wantErr error
+	}{
+		{"valid", rider, now, &Identity{UserID: "rider-1", Role: RoleRider}, nil},
+		{"just before expiry", rider, now.Add(time.Hour - time.Second), &Identity{UserID: "rider-1", Role: RoleRider}, nil},
+		{"expired", rider, now.Add(time.Hour), nil, ErrTokenExpired},
+		{"forged payload", forged, now, nil, ErrInvalidToken},
+		{"other secret", otherSecret, now, nil, ErrInvalidToken},
+		{"missing user", noUser, now, nil, ErrInvalidToken},
+		{"unknown role", badRole, now, nil, ErrInvalidToken},
+		{"not a token", "not-a-token", now, nil, ErrInvalidToken},
+		{"empty", "", now, nil, ErrInvalidToken},
+		{"other algorithm", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + ".", now, nil, ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := tokens.Verify(tt.token, tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want != nil && *identity != *tt.want {
+				t.Errorf("Verify() = %+v, want %+v", identity, tt.want)
+			}
+		})
+	}
+}
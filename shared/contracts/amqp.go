@@ -1,9 +1,15 @@
 package contracts
 
+import "time"
+
 // AmqpMessage is the message structure for AMQP.
+// MessageID and Timestamp are filled in on publish when left empty; a message published
+// again (e.g. by an outbox relay) must keep its ID so that consumers can skip it.
 type AmqpMessage struct {
-	OwnerID string `json:"ownerId"`
-	Data    []byte `json:"data"`
+	MessageID string    `json:"messageId"`
+	Timestamp time.Time `json:"timestamp"`
+	OwnerID   string    `json:"ownerId"`
+	Data      []byte    `json:"data"`
 }
 
 // Routing keys - using consistent event/command patterns
@@ -13,6 +19,7 @@ const (
 	TripEventDriverAssigned      = "trip.event.driver_assigned"
 	TripEventNoDriversFound      = "trip.event.no_drivers_found"
 	TripEventDriverNotInterested = "trip.event.driver_not_interested"
+	TripEventCancelled           = "trip.event.cancelled"
 
 	// Driver commands (driver.cmd.*)
 	DriverCmdTripRequest = "driver.cmd.trip_request"
@@ -21,11 +28,17 @@ const (
 	DriverCmdLocation    = "driver.cmd.location"
 	DriverCmdRegister    = "driver.cmd.register"
 
+	// Driver events (driver.event.*)
+	DriverEventTripCancelled   = "driver.event.trip_cancelled"
+	DriverEventLocationUpdated = "driver.event.location_updated"
+	DriverEventTripUnavailable = "driver.event.trip_unavailable"
+
 	// Payment events (payment.event.*)
 	PaymentEventSessionCreated = "payment.event.session_created"
 	PaymentEventSuccess        = "payment.event.success"
 	PaymentEventFailed         = "payment.event.failed"
 	PaymentEventCancelled      = "payment.event.cancelled"
+	PaymentEventRefunded       = "payment.event.refunded"
 
 	// Payment commands (payment.cmd.*)
 	PaymentCmdCreateSession = "payment.cmd.create_session"
@@ -1,6 +1,37 @@
 package contracts
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
+
+// WSProtocolVersion is the version of the WebSocket protocol below. Clients may ask for it
+// with the v query parameter, connections asking for another version are refused.
+const WSProtocolVersion = 1
+
+// Heartbeat of the WebSocket connections. The server pings every WSPingInterval and closes
+// connections that have not answered within WSPongTimeout, or whose writes take longer
+// than WSWriteTimeout.
+const (
+	WSPingInterval = 25 * time.Second
+	WSPongTimeout  = 60 * time.Second
+	WSWriteTimeout = 10 * time.Second
+)
+
+// Message types of the protocol itself
+const (
+	WSTypeHello = "ws.hello" // first message of every connection, data is WSHello
+	WSTypeAck   = "ws.ack"   // a client message was processed, data is WSAck
+	WSTypeError = "ws.error" // a client message was rejected, data is WSError
+)
+
+// Codes of WSError
+const (
+	WSErrorBadRequest      = "bad_request"      // the message could not be parsed
+	WSErrorUnknownType     = "unknown_type"     // nothing handles messages of this type
+	WSErrorTripUnavailable = "trip_unavailable" // the trip is no longer offered to the driver
+	WSErrorInternal        = "internal"         // the message could not be processed, it can be retried
+)
 
 // WSMessage is the message structure for the WebSocket.
 type WSMessage struct {
@@ -8,7 +39,27 @@ type WSMessage struct {
 	Data any    `json:"data"`
 }
 
+// WSDriverMessage is a message sent by a client. A client that sets ID gets a WSAck or a
+// WSError with the same ID once the message is processed.
 type WSDriverMessage struct {
+	ID   string          `json:"id,omitempty"`
 	Type string          `json:"type"`
 	Data json.RawMessage `json:"data"`
 }
+
+type WSHello struct {
+	Version        int   `json:"version"`
+	PingIntervalMs int64 `json:"pingIntervalMs"`
+	PongTimeoutMs  int64 `json:"pongTimeoutMs"`
+	ServerTime     int64 `json:"serverTime"` // unix milliseconds
+}
+
+type WSAck struct {
+	ID string `json:"id"`
+}
+
+type WSError struct {
+	ID      string `json:"id,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -13,8 +13,12 @@ import (
 )
 
 const (
-	TripsCollection     = "trips"
-	RideFaresCollection = "ride_fares"
+	TripsCollection             = "trips"
+	RideFaresCollection         = "ride_fares"
+	OutboxCollection            = "outbox"
+	PaymentsCollection          = "payments"
+	WebhookEventsCollection     = "webhook_events"
+	ProcessedMessagesCollection = "processed_messages"
 )
 
 // MongoConfig holds MongoDB connection configuration
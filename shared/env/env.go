@@ -6,6 +6,8 @@ package env
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func GetString(key, fallback string) string {
@@ -31,6 +33,20 @@ func GetInt(key string, fallback int) int {
 	return valAsInt
 }
 
+func GetFloat(key string, fallback float64) float64 {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	valAsFloat, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fallback
+	}
+
+	return valAsFloat
+}
+
 func GetBool(key string, fallback bool) bool {
 	val, ok := os.LookupEnv(key)
 	if !ok {
@@ -44,3 +60,22 @@ func GetBool(key string, fallback bool) bool {
 
 	return boolVal
 }
+
+// GetDurations reads a comma separated list of durations, such as "1s,10s,1m".
+func GetDurations(key string, fallback []time.Duration) []time.Duration {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	var durations []time.Duration
+	for _, part := range strings.Split(val, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return fallback
+		}
+		durations = append(durations, d)
+	}
+
+	return durations
+}
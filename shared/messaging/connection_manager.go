@@ -1,11 +1,15 @@
 package messaging
 
 import (
+	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
+	"ride-sharing/shared/auth"
 	"ride-sharing/shared/contracts"
 
 	"github.com/gorilla/websocket"
@@ -13,18 +17,58 @@ import (
 
 var (
 	ErrConnectionNotFound = errors.New("connection not found")
+	ErrUnauthorized       = errors.New("missing or invalid token")
+	ErrUnsupportedVersion = errors.New("unsupported protocol version")
 )
 
+// TokenVerifier checks the access token a WebSocket client connects with.
+type TokenVerifier interface {
+	Verify(token string, now time.Time) (*auth.Identity, error)
+}
+
 // connWrapper is a wrapper around the websocket connection to allow for thread-safe operations
 // This is necessary because the websocket connection is not thread-safe
 type connWrapper struct {
 	conn  *websocket.Conn
 	mutex sync.Mutex
+	done  chan struct{} // closed when the connection is removed, stops the pings
+}
+
+// write sends a message, giving up after WSWriteTimeout.
+func (w *connWrapper) write(messageType int, data []byte) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := w.conn.SetWriteDeadline(time.Now().Add(contracts.WSWriteTimeout)); err != nil {
+		return err
+	}
+	return w.conn.WriteMessage(messageType, data)
+}
+
+// ping keeps pinging the client until the connection is removed. A failed ping closes the
+// connection, which fails the pending read of its handler and unregisters the user.
+func (w *connWrapper) ping() {
+	ticker := time.NewTicker(contracts.WSPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			if err := w.write(websocket.PingMessage, nil); err != nil {
+				log.Printf("Closing stale connection: %v", err)
+				w.conn.Close()
+				return
+			}
+		}
+	}
 }
 
 type ConnectionManager struct {
 	connections map[string]*connWrapper // Local connections storage (userId -> connection)
 	mutex       sync.RWMutex
+	tokens      TokenVerifier
 }
 
 var upgrader = websocket.Upgrader{
@@ -33,36 +77,93 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-// Note that on multiple instances of the API gateway, the connection manager needs to store the connections on a separate shared storage.
-func NewConnectionManager() *ConnectionManager {
+// The connection manager only knows the connections of its own instance. With several
+// instances of the API gateway every instance receives every message (see QueueConsumer)
+// and delivers the ones whose user is connected to it.
+func NewConnectionManager(tokens TokenVerifier) *ConnectionManager {
 	return &ConnectionManager{
 		connections: make(map[string]*connWrapper),
+		tokens:      tokens,
 	}
 }
 
-func (cm *ConnectionManager) Upgrade(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
+// Upgrade verifies the token passed in the token query parameter, since browsers cannot set
+// headers on WebSocket requests, and upgrades the connection of a user with the given role.
+// Unauthorized requests are answered with 401 and ErrUnauthorized.
+func (cm *ConnectionManager) Upgrade(w http.ResponseWriter, r *http.Request, role auth.Role) (*websocket.Conn, *auth.Identity, error) {
+	if v := r.URL.Query().Get("v"); v != "" && v != strconv.Itoa(contracts.WSProtocolVersion) {
+		http.Error(w, "Unsupported protocol version, the server speaks version "+strconv.Itoa(contracts.WSProtocolVersion), http.StatusBadRequest)
+		return nil, nil, ErrUnsupportedVersion
+	}
+
+	identity, err := cm.tokens.Verify(r.URL.Query().Get("token"), time.Now())
+	if err != nil || identity.Role != role {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, ErrUnauthorized
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return conn, nil
+	return conn, identity, nil
 }
 
+// Add registers the connection of the user, replacing and closing any previous one, and
+// starts the heartbeat: the read deadline is pushed back by every pong, so the handler's
+// read fails once the client stops answering pings. It then greets the client with WSHello.
 func (cm *ConnectionManager) Add(id string, conn *websocket.Conn) {
+	wrapper := &connWrapper{
+		conn: conn,
+		done: make(chan struct{}),
+	}
+
+	conn.SetReadDeadline(time.Now().Add(contracts.WSPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(contracts.WSPongTimeout))
+	})
+
 	cm.mutex.Lock()
-	defer cm.mutex.Unlock()
-	cm.connections[id] = &connWrapper{
-		conn:  conn,
-		mutex: sync.Mutex{},
+	previous, exists := cm.connections[id]
+	cm.connections[id] = wrapper
+	cm.mutex.Unlock()
+
+	if exists {
+		close(previous.done)
+		previous.conn.Close()
 	}
 
+	go wrapper.ping()
+
 	log.Printf("Added connection for user %s", id)
+
+	if err := cm.SendMessage(id, contracts.WSMessage{
+		Type: contracts.WSTypeHello,
+		Data: contracts.WSHello{
+			Version:        contracts.WSProtocolVersion,
+			PingIntervalMs: contracts.WSPingInterval.Milliseconds(),
+			PongTimeoutMs:  contracts.WSPongTimeout.Milliseconds(),
+			ServerTime:     time.Now().UnixMilli(),
+		},
+	}); err != nil {
+		log.Printf("Failed to greet user %s: %v", id, err)
+	}
 }
 
-func (cm *ConnectionManager) Remove(id string) {
+// Remove unregisters the connection of the user, unless it has already been replaced by a newer one.
+// It reports whether the connection was removed, i.e. whether it was still the user's current one.
+func (cm *ConnectionManager) Remove(id string, conn *websocket.Conn) bool {
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
+
+	wrapper, exists := cm.connections[id]
+	if !exists || wrapper.conn != conn {
+		return false
+	}
+
+	close(wrapper.done)
 	delete(cm.connections, id)
+	return true
 }
 
 func (cm *ConnectionManager) Get(id string) (*websocket.Conn, bool) {
@@ -84,8 +185,35 @@ func (cm *ConnectionManager) SendMessage(id string, message contracts.WSMessage)
 		return ErrConnectionNotFound
 	}
 
-	wrapper.mutex.Lock()
-	defer wrapper.mutex.Unlock()
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return wrapper.write(websocket.TextMessage, data)
+}
+
+// SendAck tells the user their message was processed.
+func (cm *ConnectionManager) SendAck(id, messageID string) error {
+	if messageID == "" {
+		return nil
+	}
+
+	return cm.SendMessage(id, contracts.WSMessage{
+		Type: contracts.WSTypeAck,
+		Data: contracts.WSAck{ID: messageID},
+	})
+}
 
-	return wrapper.conn.WriteJSON(message)
+// SendError tells the user their message was rejected. Messages without an ID get an error too,
+// since a client may not be able to tell which message failed to parse.
+func (cm *ConnectionManager) SendError(id, messageID, code, message string) error {
+	return cm.SendMessage(id, contracts.WSMessage{
+		Type: contracts.WSTypeError,
+		Data: contracts.WSError{
+			ID:      messageID,
+			Code:    code,
+			Message: message,
+		},
+	})
 }
@@ -0,0 +1,121 @@
+package messaging
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultDedupeCapacity = 10000
+	// DefaultDedupeTTL outlasts the retry tiers and the outbox relay by far.
+	DefaultDedupeTTL = 24 * time.Hour
+)
+
+// DedupeStore remembers which messages a consumer has already processed, so that
+// redelivered or republished messages are not handled twice. Consumers are identified
+// by their queue, as the same message is delivered once to every bound queue.
+type DedupeStore interface {
+	Seen(ctx context.Context, consumer, messageID string) (bool, error)
+	MarkProcessed(ctx context.Context, consumer, messageID string) error
+}
+
+// memoryDedupeStore keeps the most recently processed message IDs in an LRU list.
+// It only protects a single process and forgets everything on restart.
+type memoryDedupeStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List               // most recently processed first
+	entries  map[string]*list.Element // consumer/messageID -> element of order
+}
+
+func NewMemoryDedupeStore(capacity int) *memoryDedupeStore {
+	return &memoryDedupeStore{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (s *memoryDedupeStore) Seen(ctx context.Context, consumer, messageID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[dedupeKey(consumer, messageID)]
+	if ok {
+		s.order.MoveToFront(elem)
+	}
+	return ok, nil
+}
+
+func (s *memoryDedupeStore) MarkProcessed(ctx context.Context, consumer, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := dedupeKey(consumer, messageID)
+	if elem, ok := s.entries[key]; ok {
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.entries[key] = s.order.PushFront(key)
+
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(string))
+	}
+
+	return nil
+}
+
+// mongoDedupeStore keeps processed message IDs in a collection shared by every
+// instance of a service. A TTL index removes them once redelivery is no longer expected.
+type mongoDedupeStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDedupeStore(ctx context.Context, db *mongo.Database, collection string, ttl time.Duration) (*mongoDedupeStore, error) {
+	coll := db.Collection(collection)
+
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "processedAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(ttl.Seconds())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TTL index on %s: %w", collection, err)
+	}
+
+	return &mongoDedupeStore{collection: coll}, nil
+}
+
+func (s *mongoDedupeStore) Seen(ctx context.Context, consumer, messageID string) (bool, error) {
+	err := s.collection.FindOne(ctx, bson.M{"_id": dedupeKey(consumer, messageID)}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *mongoDedupeStore) MarkProcessed(ctx context.Context, consumer, messageID string) error {
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": dedupeKey(consumer, messageID)},
+		bson.M{"$set": bson.M{"processedAt": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func dedupeKey(consumer, messageID string) string {
+	return consumer + "/" + messageID
+}
@@ -0,0 +1,70 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestMemoryDedupeStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryDedupeStore(2)
+
+	mark := func(consumer, messageID string) {
+		t.Helper()
+		if err := store.MarkProcessed(ctx, consumer, messageID); err != nil {
+			t.Fatalf("MarkProcessed(%s, %s) = %v", consumer, messageID, err)
+		}
+	}
+
+	mark("find_available_drivers", "msg-1")
+	mark("find_available_drivers", "msg-2")
+	// seeing msg-1 again makes msg-2 the least recently used
+	if seen, _ := store.Seen(ctx, "find_available_drivers", "msg-1"); !seen {
+		t.Fatal("msg-1 was not seen")
+	}
+	mark("find_available_drivers", "msg-3")
+
+	tests := []struct {
+		consumer, messageID string
+		want                bool
+	}{
+		{"find_available_drivers", "msg-1", true},
+		{"find_available_drivers", "msg-2", false}, // evicted
+		{"find_available_drivers", "msg-3", true},
+		{"payment_trip_response", "msg-1", false}, // every consumer processes the message once
+		{"find_available_drivers", "msg-4", false},
+	}
+
+	for _, tt := range tests {
+		seen, err := store.Seen(ctx, tt.consumer, tt.messageID)
+		if err != nil {
+			t.Fatalf("Seen() = %v", err)
+		}
+		if seen != tt.want {
+			t.Errorf("Seen(%s, %s) = %v, want %v", tt.consumer, tt.messageID, seen, tt.want)
+		}
+	}
+}
+
+func TestDeliveryMessageID(t *testing.T) {
+	tests := []struct {
+		name string
+		d    amqp.Delivery
+		want string
+	}{
+		{"from the properties", amqp.Delivery{MessageId: "props-id", Body: []byte(`{"messageId":"body-id"}`)}, "props-id"},
+		{"from the body", amqp.Delivery{Body: []byte(`{"ownerId":"rider-1","messageId":"body-id"}`)}, "body-id"},
+		{"without an ID", amqp.Delivery{Body: []byte(`{"ownerId":"rider-1"}`)}, ""},
+		{"not json", amqp.Delivery{Body: []byte(`not json`)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliveryMessageID(tt.d); got != tt.want {
+				t.Errorf("deliveryMessageID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const DefaultPublishTimeout = 5 * time.Second
+
+var (
+	ErrUnroutable    = errors.New("message could not be routed to any queue")
+	ErrPublishNacked = errors.New("broker refused the message")
+)
+
+// UnroutableError is returned by PublishMessage when no queue is bound to the routing key,
+// so the broker returned the message instead of storing it.
+type UnroutableError struct {
+	Exchange   string
+	RoutingKey string
+	ReplyCode  uint16
+	ReplyText  string
+}
+
+func (e *UnroutableError) Error() string {
+	return fmt.Sprintf("message to %s with routing key %q was returned: %d %s", e.Exchange, e.RoutingKey, e.ReplyCode, e.ReplyText)
+}
+
+func (e *UnroutableError) Is(target error) bool {
+	return target == ErrUnroutable
+}
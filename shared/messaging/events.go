@@ -6,43 +6,84 @@ import (
 )
 
 const (
-	FindAvailableDriversQueue        = "find_available_drivers"
-	DriverCmdTripRequestQueue        = "driver_cmd_trip_request"
-	DriverTripResponseQueue          = "driver_trip_response"
-	NotifyDriverNoDriversFoundQueue  = "notify_driver_no_drivers_found"
-	NotifyDriverAssignQueue          = "notify_driver_assign"
-	PaymentTripResponseQueue         = "payment_trip_response"
-	NotifyPaymentSessionCreatedQueue = "notify_payment_session_created"
-	NotifyPaymentSuccessQueue        = "payment_success"
+	FindAvailableDriversQueue = "find_available_drivers"
+	DriverTripResponseQueue   = "driver_trip_response"
+	PaymentTripResponseQueue  = "payment_trip_response"
+	TripPaymentStatusQueue    = "trip_payment_status"
+	PaymentStatusUpdateQueue  = "payment_status_update"
+	DriverTripCancelledQueue  = "driver_trip_cancelled"
+	PaymentTripCancelledQueue = "payment_trip_cancelled"
+	DriverTripAssignedQueue   = "driver_trip_assigned"
+	DriverTripPaidQueue       = "driver_trip_paid"
+	TripNoDriversFoundQueue   = "trip_no_drivers_found"
 )
 const DeadLetterQueue = "dead_letter_queue"
 
+// GatewayQueuePrefix names the exclusive queue of every api-gateway instance, see QueueConsumer.
+const GatewayQueuePrefix = "api_gateway"
+
 type TripEventData struct {
 	Trip *pb.Trip `json:"trip"`
 }
 
+type TripDriverNotInterestedData struct {
+	Trip     *pb.Trip `json:"trip"`
+	DriverID string   `json:"driverID"`
+}
+
+// Who cancelled a trip, carried in TripCancelledData.CancelledBy
+const (
+	CancelledByRider  = "rider"
+	CancelledByDriver = "driver"
+)
+
+type TripCancelledData struct {
+	Trip                   *pb.Trip `json:"trip"`
+	CancelledBy            string   `json:"cancelledBy"`
+	CancellationFeeInCents int64    `json:"cancellationFeeInCents"`
+}
+
 type DriverTripResponseData struct {
 	Driver  pbd.Driver `json:"driver"`
 	TripID  string     `json:"tripID"`
 	RiderID string     `json:"riderID"`
 }
 
-type PaymentEventSessionCreatedData struct {
-	TripID    string  `json:"tripID"`
-	SessionID string  `json:"sessionID"`
-	Amount    float64 `json:"amount"`
-	Currency  string  `json:"currency"`
+type DriverLocationUpdatedData struct {
+	DriverID string        `json:"driverID"`
+	TripID   string        `json:"tripID"`
+	Location *pbd.Location `json:"location"`
+	Geohash  string        `json:"geohash"`
 }
 
-type PaymentTripResponseData struct {
-	TripID   string  `json:"tripID"`
-	UserID   string  `json:"userID"`
-	DriverID string  `json:"driverID"`
-	Amount   float64 `json:"amount"`
+type PaymentEventSessionCreatedData struct {
+	TripID      string  `json:"tripID"`
+	SessionID   string  `json:"sessionID"`
+	CheckoutURL string  `json:"checkoutURL,omitempty"` // where the rider pays the session
+	Amount      float64 `json:"amount"`                // in major units of Currency, e.g. 12.5 usd
+	Currency    string  `json:"currency"`
 }
 
-type PaymentStatusUpdateData struct {
+// PaymentTripResponseData is the payload of payment.cmd.create_session
+type PaymentTripResponseData struct {
 	TripID   string `json:"tripID"`
 	UserID   string `json:"userID"`
 	DriverID string `json:"driverID"`
+	Amount   int64  `json:"amount"`   // in minor units of Currency
+	Currency string `json:"currency"` // lowercase ISO 4217 code
+}
+
+// PaymentTypeCancellationFee marks, in PaymentStatusUpdateData.PaymentType, the payments of
+// cancellation fees. Ride payments leave it empty.
+const PaymentTypeCancellationFee = "cancellation_fee"
+
+// PaymentStatusUpdateData is the payload of the payment.event.* messages reporting the
+// outcome of a checkout session. Refunds are reported per trip and carry no SessionID.
+type PaymentStatusUpdateData struct {
+	TripID      string `json:"tripID"`
+	UserID      string `json:"userID"`
+	DriverID    string `json:"driverID"`
+	SessionID   string `json:"sessionID,omitempty"`
+	PaymentType string `json:"paymentType,omitempty"`
+	Reason      string `json:"reason,omitempty"`
 }
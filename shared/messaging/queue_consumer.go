@@ -2,31 +2,99 @@ package messaging
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 
 	"ride-sharing/shared/contracts"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// QueueConsumer forwards messages to the WebSocket of their OwnerID. Every gateway instance
+// runs a single QueueConsumer on its own exclusive queue, bound to the routing keys it forwards,
+// so every instance receives every message and delivers the ones whose owner is connected to it.
 type QueueConsumer struct {
-	rb        *RabbitMQ
-	connMgr   *ConnectionManager
-	queueName string
+	rb          *RabbitMQ
+	connMgr     *ConnectionManager
+	queueName   string
+	routingKeys []string
+	consumerTag string
+
+	id int
 }
 
-func NewQueueConsumer(rb *RabbitMQ, connMgr *ConnectionManager, queueName string) *QueueConsumer {
+// NewQueueConsumer creates a consumer on a queue named after prefix and unique to this instance.
+func NewQueueConsumer(rb *RabbitMQ, connMgr *ConnectionManager, prefix string, routingKeys []string) *QueueConsumer {
+	id := uuid.NewString()
+
 	return &QueueConsumer{
-		rb:        rb,
-		connMgr:   connMgr,
-		queueName: queueName,
+		rb:          rb,
+		connMgr:     connMgr,
+		queueName:   fmt.Sprintf("%s.%s", prefix, id),
+		routingKeys: routingKeys,
+		consumerTag: id,
 	}
 }
 
+// Start registers the consumer with the connection, so that it keeps forwarding
+// messages after the connection to RabbitMQ is restored.
 func (qc *QueueConsumer) Start() error {
-	msgs, err := qc.rb.Channel.Consume(
+	id, err := qc.rb.registerConsumer(qc.consume)
+	if err != nil {
+		return err
+	}
+
+	qc.id = id
+	return nil
+}
+
+// Stop cancels the consumer, which also deletes its queue.
+func (qc *QueueConsumer) Stop() error {
+	qc.rb.unregisterConsumer(qc.id)
+
+	ch, err := qc.rb.currentChannel()
+	if err != nil {
+		// the queue went away with the connection
+		return nil
+	}
+
+	return ch.Cancel(qc.consumerTag, false)
+}
+
+// consume declares the queue again on every connection: an exclusive queue is deleted
+// together with the connection that declared it.
+func (qc *QueueConsumer) consume(ch *amqp.Channel) error {
+	_, err := ch.QueueDeclare(
+		qc.queueName, // name
+		false,        // durable
+		true,         // delete when unused
+		true,         // exclusive
+		false,        // no-wait
+		nil,          // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", qc.queueName, err)
+	}
+
+	for _, routingKey := range qc.routingKeys {
+		if err := ch.QueueBind(
+			qc.queueName, // queue name
+			routingKey,   // routing key
+			TripExchange, // exchange
+			false,        // no-wait
+			nil,          // arguments
+		); err != nil {
+			return fmt.Errorf("failed to bind queue %s to exchange %s: %w", qc.queueName, TripExchange, err)
+		}
+	}
+
+	msgs, err := ch.Consume(
 		qc.queueName,
-		"",
+		qc.consumerTag,
+		true,
 		true,
-		false,
 		false,
 		false,
 		nil,
@@ -58,7 +126,12 @@ func (qc *QueueConsumer) Start() error {
 				Data: payload,
 			}
 
-			if err := qc.connMgr.SendMessage(userID, clientMsg); err != nil {
+			err := qc.connMgr.SendMessage(userID, clientMsg)
+			if errors.Is(err, ErrConnectionNotFound) {
+				// the user is connected to another instance, or not at all
+				continue
+			}
+			if err != nil {
 				log.Printf("Failed to send message to user %s: %v", userID, err)
 			}
 		}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"log"
 	"ride-sharing/shared/contracts"
-	"ride-sharing/shared/retry"
 	"ride-sharing/shared/tracing"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
@@ -17,39 +19,61 @@ const (
 	DeadLetterExchange = "dlx"
 )
 
+// RabbitMQ is a supervised connection to the broker. When the connection or its channel
+// closes it reconnects with backoff, redeclares the topology and restarts every consumer
+// registered through ConsumeMessages or QueueConsumer.Start.
 type RabbitMQ struct {
-	conn    *amqp.Connection
-	Channel *amqp.Channel
+	uri         string
+	dedupe      DedupeStore
+	retryDelays []time.Duration
+
+	mu             sync.RWMutex
+	conn           *amqp.Connection
+	channel        *amqp.Channel // consumers and topology
+	publishChannel *amqp.Channel // publishing, in confirm mode
+	returns        <-chan amqp.Return
+	ready          bool
+	readyCh        chan struct{} // closed while the connection is ready
+	consumers      map[int]*consumer
+	nextID         int
+
+	// publishes wait for their confirm one at a time, which ties every
+	// unroutable return to the publish that caused it
+	publishMu      sync.Mutex
+	publishTimeout time.Duration
+
+	done chan struct{}
 }
 
-func NewRabbitMQ(uri string) (*RabbitMQ, error) {
-	conn, err := amqp.Dial(uri)
+func NewRabbitMQ(uri string, retry RetryConfig) (*RabbitMQ, error) {
+	retryDelays, err := retry.tiers()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
-	}
-
-	channel, err := conn.Channel()
-	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to open a channel: %w", err)
+		return nil, fmt.Errorf("invalid retry config: %w", err)
 	}
 
 	rabbitmq := &RabbitMQ{
-		conn:    conn,
-		Channel: channel,
+		uri:         uri,
+		dedupe:      NewMemoryDedupeStore(DefaultDedupeCapacity),
+		retryDelays: retryDelays,
+		readyCh:     make(chan struct{}),
+		consumers:   make(map[int]*consumer),
+		done:        make(chan struct{}),
+
+		publishTimeout: DefaultPublishTimeout,
 	}
 
-	if err := rabbitmq.setupExchangesAndQueues(); err != nil {
-		channel.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to set up exchanges and queues: %w", err)
+	closed, err := rabbitmq.connect()
+	if err != nil {
+		return nil, err
 	}
 
+	go rabbitmq.supervise(closed)
+
 	return rabbitmq, nil
 }
 
 func (r *RabbitMQ) setupDeadLetterExchange() error {
-	err := r.Channel.ExchangeDeclare(
+	err := r.channel.ExchangeDeclare(
 		DeadLetterExchange, // name
 		"topic",            // type
 		true,               // durable
@@ -62,7 +86,7 @@ func (r *RabbitMQ) setupDeadLetterExchange() error {
 		return fmt.Errorf("failed to declare exchange: %s: %v", DeadLetterExchange, err)
 	}
 
-	q, err := r.Channel.QueueDeclare(
+	q, err := r.channel.QueueDeclare(
 		DeadLetterQueue, // name
 		true,            // durable
 		false,           // delete when unused
@@ -77,7 +101,7 @@ func (r *RabbitMQ) setupDeadLetterExchange() error {
 
 	// Bind the dead letter queue to the dead letter exchange with a wildcard routing key
 
-	err = r.Channel.QueueBind(
+	err = r.channel.QueueBind(
 		q.Name,             // queue name
 		"#",                // routing key
 		DeadLetterExchange, // exchange
@@ -92,12 +116,20 @@ func (r *RabbitMQ) setupDeadLetterExchange() error {
 	return nil
 }
 
+// paymentOutcomeRoutingKeys are the events reporting how a checkout session ended
+var paymentOutcomeRoutingKeys = []string{
+	contracts.PaymentEventSuccess,
+	contracts.PaymentEventFailed,
+	contracts.PaymentEventCancelled,
+	contracts.PaymentEventRefunded,
+}
+
 func (r *RabbitMQ) setupExchangesAndQueues() error {
 
 	if err := r.setupDeadLetterExchange(); err != nil {
 		return fmt.Errorf("failed to setup dead letter exchange: %w", err)
 	}
-	err := r.Channel.ExchangeDeclare(
+	err := r.channel.ExchangeDeclare(
 		TripExchange, // name
 		"topic",      // type
 		true,         // durable
@@ -121,61 +153,77 @@ func (r *RabbitMQ) setupExchangesAndQueues() error {
 	}
 
 	if err := r.declareAndBindQueue(
-		DriverCmdTripRequestQueue,
-		[]string{contracts.DriverCmdTripRequest},
+		DriverTripResponseQueue,
+		[]string{contracts.DriverCmdTripAccept, contracts.DriverCmdTripDecline},
 		TripExchange,
 	); err != nil {
 		return err
 	}
 
 	if err := r.declareAndBindQueue(
-		DriverTripResponseQueue,
-		[]string{contracts.DriverCmdTripAccept, contracts.DriverCmdTripDecline},
+		PaymentTripResponseQueue,
+		[]string{contracts.PaymentCmdCreateSession},
 		TripExchange,
 	); err != nil {
 		return err
 	}
 
 	if err := r.declareAndBindQueue(
-		NotifyDriverNoDriversFoundQueue,
-		[]string{contracts.TripEventNoDriversFound},
+		TripPaymentStatusQueue,
+		paymentOutcomeRoutingKeys,
 		TripExchange,
 	); err != nil {
 		return err
 	}
 
 	if err := r.declareAndBindQueue(
-		NotifyDriverAssignQueue,
-		[]string{contracts.TripEventDriverAssigned},
+		PaymentStatusUpdateQueue,
+		paymentOutcomeRoutingKeys,
 		TripExchange,
 	); err != nil {
 		return err
 	}
 
 	if err := r.declareAndBindQueue(
-		PaymentTripResponseQueue,
-		[]string{contracts.PaymentCmdCreateSession},
+		DriverTripCancelledQueue,
+		[]string{contracts.TripEventCancelled},
+		TripExchange,
+	); err != nil {
+		return err
+	}
+
+	if err := r.declareAndBindQueue(
+		PaymentTripCancelledQueue,
+		[]string{contracts.TripEventCancelled},
 		TripExchange,
 	); err != nil {
 		return err
 	}
 
 	if err := r.declareAndBindQueue(
-		NotifyPaymentSessionCreatedQueue,
-		[]string{contracts.PaymentEventSessionCreated},
+		DriverTripAssignedQueue,
+		[]string{contracts.TripEventDriverAssigned},
 		TripExchange,
 	); err != nil {
 		return err
 	}
 
 	if err := r.declareAndBindQueue(
-		NotifyPaymentSuccessQueue,
+		DriverTripPaidQueue,
 		[]string{contracts.PaymentEventSuccess},
 		TripExchange,
 	); err != nil {
 		return err
 	}
 
+	if err := r.declareAndBindQueue(
+		TripNoDriversFoundQueue,
+		[]string{contracts.TripEventNoDriversFound},
+		TripExchange,
+	); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -184,7 +232,7 @@ func (r *RabbitMQ) declareAndBindQueue(queueName string, messageTypes []string,
 	args := amqp.Table{
 		"x-dead-letter-exchange": DeadLetterExchange,
 	}
-	_, err := r.Channel.QueueDeclare(
+	_, err := r.channel.QueueDeclare(
 		queueName, // name
 		true,      // durable
 		false,     // delete when unused
@@ -197,8 +245,12 @@ func (r *RabbitMQ) declareAndBindQueue(queueName string, messageTypes []string,
 		return fmt.Errorf("failed to declare queue %s: %w", queueName, err)
 	}
 
+	if err := r.declareRetryQueues(queueName); err != nil {
+		return err
+	}
+
 	for _, messageType := range messageTypes {
-		if err := r.Channel.QueueBind(
+		if err := r.channel.QueueBind(
 			queueName,   // queue name
 			messageType, // routing key
 			exchange,    // exchange
@@ -214,12 +266,40 @@ func (r *RabbitMQ) declareAndBindQueue(queueName string, messageTypes []string,
 
 type MessageHandler func(ctx context.Context, msg amqp.Delivery) error
 
+// SetDedupeStore replaces the store consumers use to skip messages they already processed.
+// It must be called before ConsumeMessages; nil turns deduplication off.
+func (r *RabbitMQ) SetDedupeStore(store DedupeStore) {
+	r.dedupe = store
+}
+
+// deliveryMessageID returns the ID of the message, taken from the AMQP properties or,
+// for messages published before IDs were set there, from the body.
+func deliveryMessageID(d amqp.Delivery) string {
+	if d.MessageId != "" {
+		return d.MessageId
+	}
+
+	var message contracts.AmqpMessage
+	if err := json.Unmarshal(d.Body, &message); err != nil {
+		return ""
+	}
+
+	return message.MessageID
+}
+
 func (r *RabbitMQ) ConsumeMessages(queueName string, handler MessageHandler) error {
+	_, err := r.registerConsumer(func(ch *amqp.Channel) error {
+		return r.consume(ch, queueName, handler)
+	})
+	return err
+}
+
+func (r *RabbitMQ) consume(ch *amqp.Channel, queueName string, handler MessageHandler) error {
 
 	// Set prefetch count to 1 for fair dispatch
 	// This tells RabbitMQ not to give more than one message to a service at a time.
 	// The worker will only get the next message after it has acknowledged the previous one.
-	err := r.Channel.Qos(
+	err := ch.Qos(
 		1,     // prefetchCount: Limit to 1 unacknowledged message per consumer
 		0,     // prefetchSize: No specific limit on message size
 		false, // global: Apply prefetchCount to each consumer individually
@@ -228,7 +308,7 @@ func (r *RabbitMQ) ConsumeMessages(queueName string, handler MessageHandler) err
 		return fmt.Errorf("failed to set QoS: %v", err)
 	}
 
-	msgs, err := r.Channel.Consume(
+	msgs, err := ch.Consume(
 		queueName, // queue
 		"",        // consumer
 		false,     // auto-ack
@@ -245,35 +325,37 @@ func (r *RabbitMQ) ConsumeMessages(queueName string, handler MessageHandler) err
 	go func() {
 		for msg := range msgs {
 			if err := tracing.TracedConsumer(msg, func(ctx context.Context, d amqp.Delivery) error {
+				restoreRouting(&d)
 
-				cfg := retry.DefaultConfig()
-
-				err := retry.WithBackoff(ctx, cfg, func() error {
-					return handler(ctx, d)
-				})
-
-				if err != nil {
-					log.Printf("ERROR: Failed to handle message: %v. Message body: %s", err, msg.Body)
-
-					headers := amqp.Table{}
+				messageID := deliveryMessageID(d)
 
-					if d.Headers != nil {
-						headers = d.Headers
+				if r.dedupe != nil && messageID != "" {
+					seen, err := r.dedupe.Seen(ctx, queueName, messageID)
+					if err != nil {
+						log.Printf("ERROR: Failed to check message %s for duplicates, processing it: %v", messageID, err)
 					}
 
-					headers["x-death-reason"] = err.Error()
-					headers["x-original-exchange"] = d.Exchange
-					headers["x-original-routing-key"] = d.RoutingKey
-					headers["x-retry-count"] = cfg.MaxRetries
-
-					d.Headers = headers
-
-					// reject without requeue - message will go to DLQ
+					if seen {
+						log.Printf("Skipping duplicate message %s on queue %s", messageID, queueName)
+						if ackErr := msg.Ack(false); ackErr != nil {
+							log.Printf("ERROR: Failed to Ack message: %v. Message body: %s", ackErr, msg.Body)
+						}
+						return nil
+					}
+				}
 
-					_ = d.Reject(false)
+				if err := handler(ctx, d); err != nil {
+					// the message waits in a retry queue instead of blocking this one
+					r.retryLater(ctx, queueName, d, err)
 					return err
 				}
 
+				if r.dedupe != nil && messageID != "" {
+					if err := r.dedupe.MarkProcessed(ctx, queueName, messageID); err != nil {
+						log.Printf("ERROR: Failed to record message %s as processed: %v", messageID, err)
+					}
+				}
+
 				// Only Ack if the handler succeeds
 				if ackErr := msg.Ack(false); ackErr != nil {
 					log.Printf("ERROR: Failed to Ack message: %v. Message body: %s", ackErr, msg.Body)
@@ -289,7 +371,16 @@ func (r *RabbitMQ) ConsumeMessages(queueName string, handler MessageHandler) err
 	return nil
 }
 
+// PublishMessage returns once the broker has confirmed the message. An error means the
+// message may not have been stored: see publish for the typed errors.
 func (r *RabbitMQ) PublishMessage(ctx context.Context, routingKey string, message *contracts.AmqpMessage) error {
+	if message.MessageID == "" {
+		message.MessageID = uuid.NewString()
+	}
+	if message.Timestamp.IsZero() {
+		message.Timestamp = time.Now().UTC()
+	}
+
 	jsonMessage, err := json.Marshal(message)
 
 	if err != nil {
@@ -300,32 +391,87 @@ func (r *RabbitMQ) PublishMessage(ctx context.Context, routingKey string, messag
 		ContentType:  "application/json",
 		Body:         jsonMessage,
 		DeliveryMode: amqp.Persistent, // ensure message is persistent
+		MessageId:    message.MessageID,
+		Timestamp:    message.Timestamp,
 	}
 
 	return tracing.TracedPublisher(ctx, TripExchange, routingKey, msg, r.publish)
 }
 
+// publish sends the message as mandatory and waits for the broker to confirm it.
+// It fails with an UnroutableError when no queue is bound to the routing key, with
+// ErrPublishNacked when the broker refuses the message, or when no confirm arrives
+// within the publish timeout.
 func (r *RabbitMQ) publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
-	err := r.Channel.PublishWithContext(ctx,
+	r.publishMu.Lock()
+	defer r.publishMu.Unlock()
+
+	ch, returns, err := r.currentPublishChannel()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.publishTimeout)
+	defer cancel()
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx,
 		exchange,   // exchange
 		routingKey, // routing key
-		false,      // mandatory
+		true,       // mandatory
 		false,      // immediate
 		msg,        // message
 	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
 
-	return err
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("no publisher confirm for message %s: %w", msg.MessageId, err)
+	}
+
+	// the broker sends a return before the confirm of the same message
+	for {
+		select {
+		case ret := <-returns:
+			if ret.MessageId != msg.MessageId {
+				// left over from a publish that timed out
+				continue
+			}
+			return &UnroutableError{
+				Exchange:   exchange,
+				RoutingKey: routingKey,
+				ReplyCode:  ret.ReplyCode,
+				ReplyText:  ret.ReplyText,
+			}
+		default:
+		}
+		break
+	}
+
+	if !acked {
+		return fmt.Errorf("%w: %s", ErrPublishNacked, msg.MessageId)
+	}
+
+	return nil
 }
 
+// Close stops the supervisor and closes the connection, which also stops every consumer.
 func (r *RabbitMQ) Close() error {
-	if r.conn != nil {
-		return r.conn.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	default:
+		close(r.done)
 	}
 
-	if r.Channel != nil {
-		if err := r.Channel.Close(); err != nil {
-			return fmt.Errorf("failed to close channel: %w", err)
-		}
+	r.setReady(false)
+
+	if r.conn != nil {
+		return r.conn.Close()
 	}
 	return nil
 }
@@ -0,0 +1,211 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"ride-sharing/shared/env"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Headers carried by a message while it is being retried
+const (
+	AttemptHeader            = "x-attempt"              // deliveries so far, 1 on the first one
+	LastErrorHeader          = "x-last-error"           // error returned by the last failed attempt
+	OriginalExchangeHeader   = "x-original-exchange"    // exchange the message was first published to
+	OriginalRoutingKeyHeader = "x-original-routing-key" // routing key the message was first published with
+)
+
+// Headers added to a message when it is dead-lettered
+const (
+	DeathReasonHeader   = "x-death-reason"   // error returned by the last attempt
+	OriginalQueueHeader = "x-original-queue" // queue whose consumer gave up on the message
+	FailedAtHeader      = "x-failed-at"      // when the consumer gave up on the message
+)
+
+// DefaultRetryDelays are the retry tiers of every queue: a failed message waits in the
+// tier of its attempt and is then dead-lettered back to its queue. A message that fails
+// once more after the last tier goes to the dead letter queue.
+var DefaultRetryDelays = []time.Duration{
+	1 * time.Second,
+	10 * time.Second,
+	1 * time.Minute,
+}
+
+// RetryConfig sets how many times a failed message is delivered and how long it waits
+// between deliveries.
+type RetryConfig struct {
+	Delays      []time.Duration // wait before each retry, the last one repeats if there are more retries
+	MaxAttempts int             // deliveries before the message is dead-lettered, the first one included
+}
+
+// NewRetryDefaultConfig reads RABBITMQ_RETRY_DELAYS, e.g. "1s,10s,1m", and RABBITMQ_MAX_ATTEMPTS,
+// which defaults to one attempt per delay plus the first delivery.
+func NewRetryDefaultConfig() RetryConfig {
+	delays := env.GetDurations("RABBITMQ_RETRY_DELAYS", DefaultRetryDelays)
+
+	return RetryConfig{
+		Delays:      delays,
+		MaxAttempts: env.GetInt("RABBITMQ_MAX_ATTEMPTS", len(delays)+1),
+	}
+}
+
+// tiers returns the delay before each retry, one per attempt but the last.
+func (c RetryConfig) tiers() ([]time.Duration, error) {
+	if c.MaxAttempts < 1 {
+		return nil, fmt.Errorf("max attempts must be at least 1, got %d", c.MaxAttempts)
+	}
+	if c.MaxAttempts > 1 && len(c.Delays) == 0 {
+		return nil, fmt.Errorf("%d attempts need at least one retry delay", c.MaxAttempts)
+	}
+	for _, delay := range c.Delays {
+		if delay <= 0 {
+			return nil, fmt.Errorf("retry delays must be positive, got %s", delay)
+		}
+	}
+
+	tiers := make([]time.Duration, c.MaxAttempts-1)
+	for i := range tiers {
+		tiers[i] = c.Delays[min(i, len(c.Delays)-1)]
+	}
+
+	return tiers, nil
+}
+
+// retryQueueName names the tier by its delay, so changing the delays declares new
+// queues instead of clashing with the TTL of the existing ones.
+func retryQueueName(queueName string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", queueName, delay)
+}
+
+// declareRetryQueues declares the retry tiers of the queue. They have no consumer: messages
+// expire after the tier's delay and are dead-lettered to the queue through the default exchange.
+// Attempts sharing a delay share its tier.
+func (r *RabbitMQ) declareRetryQueues(queueName string) error {
+	for _, delay := range r.retryDelays {
+		name := retryQueueName(queueName, delay)
+
+		_, err := r.channel.QueueDeclare(
+			name,  // name
+			true,  // durable
+			false, // delete when unused
+			false, // exclusive
+			false, // no-wait
+			amqp.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queueName,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare retry queue %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// deliveryAttempt returns how many times the message has been delivered, this one included.
+func deliveryAttempt(d amqp.Delivery) int {
+	switch v := d.Headers[AttemptHeader].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 1
+	}
+}
+
+// restoreRouting puts back the exchange and routing key a retried message was first
+// published with. The retry tiers dead-letter through the default exchange, so the message
+// comes back routed by the queue name, which handlers switching on the routing key ignore.
+func restoreRouting(d *amqp.Delivery) {
+	routingKey, ok := d.Headers[OriginalRoutingKeyHeader].(string)
+	if !ok {
+		return
+	}
+
+	d.RoutingKey = routingKey
+	if exchange, ok := d.Headers[OriginalExchangeHeader].(string); ok {
+		d.Exchange = exchange
+	}
+}
+
+// failureHeaders copies the headers of the delivery and records where the message was
+// first published, which retries and dead-lettering would otherwise lose.
+func failureHeaders(d amqp.Delivery, handlerErr error) amqp.Table {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+
+	if _, ok := headers[OriginalRoutingKeyHeader]; !ok {
+		headers[OriginalExchangeHeader] = d.Exchange
+		headers[OriginalRoutingKeyHeader] = d.RoutingKey
+	}
+	headers[LastErrorHeader] = handlerErr.Error()
+
+	return headers
+}
+
+// republish sends a copy of the delivery with the given headers and acks the original.
+// When the copy cannot be published the original is rejected, so the queue still
+// dead-letters it, only without the failure headers.
+func (r *RabbitMQ) republish(ctx context.Context, exchange, routingKey string, d amqp.Delivery, headers amqp.Table) error {
+	err := r.publish(ctx, exchange, routingKey, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  d.ContentType,
+		Body:         d.Body,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    d.MessageId,
+		Timestamp:    d.Timestamp,
+	})
+	if err != nil {
+		if rejectErr := d.Reject(false); rejectErr != nil {
+			log.Printf("ERROR: Failed to Reject message: %v. Message body: %s", rejectErr, d.Body)
+		}
+		return err
+	}
+
+	if err := d.Ack(false); err != nil {
+		log.Printf("ERROR: Failed to Ack message: %v. Message body: %s", err, d.Body)
+	}
+	return nil
+}
+
+// retryLater moves a message whose handler failed to the retry tier of its attempt, so
+// the queue keeps flowing. Once the tiers are exhausted the message is published to the
+// dead letter exchange with headers describing the failure.
+func (r *RabbitMQ) retryLater(ctx context.Context, queueName string, d amqp.Delivery, handlerErr error) {
+	attempt := deliveryAttempt(d)
+	headers := failureHeaders(d, handlerErr)
+
+	if attempt > len(r.retryDelays) {
+		log.Printf("ERROR: Message %s failed %d times on queue %s, dead-lettering it: %v", d.MessageId, attempt, queueName, handlerErr)
+
+		headers[DeathReasonHeader] = handlerErr.Error()
+		headers[OriginalQueueHeader] = queueName
+		headers[FailedAtHeader] = time.Now().UTC().Format(time.RFC3339)
+
+		routingKey, _ := headers[OriginalRoutingKeyHeader].(string)
+		if err := r.republish(ctx, DeadLetterExchange, routingKey, d, headers); err != nil {
+			log.Printf("ERROR: Failed to publish message %s to %s: %v", d.MessageId, DeadLetterExchange, err)
+		}
+		return
+	}
+
+	headers[AttemptHeader] = int32(attempt + 1)
+
+	retryQueue := retryQueueName(queueName, r.retryDelays[attempt-1])
+	if err := r.republish(ctx, "", retryQueue, d, headers); err != nil {
+		log.Printf("ERROR: Failed to move message %s to %s, dead-lettering it: %v", d.MessageId, retryQueue, err)
+		return
+	}
+
+	log.Printf("Message %s failed attempt %d on queue %s, retrying in %s: %v", d.MessageId, attempt, queueName, r.retryDelays[attempt-1], handlerErr)
+}
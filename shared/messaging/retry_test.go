@@ -0,0 +1,119 @@
+package messaging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ride-sharing/shared/contracts"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// redeliver returns the delivery a consumer of queueName gets after the message waited in
+// a retry tier, which dead-letters it through the default exchange to the queue.
+func redeliver(queueName string, d amqp.Delivery, handlerErr error) amqp.Delivery {
+	headers := failureHeaders(d, handlerErr)
+	headers[AttemptHeader] = int32(deliveryAttempt(d) + 1)
+
+	return amqp.Delivery{
+		Headers:    headers,
+		Exchange:   "",
+		RoutingKey: queueName,
+		MessageId:  d.MessageId,
+		Body:       d.Body,
+	}
+}
+
+func TestRestoreRoutingAfterRetries(t *testing.T) {
+	const queueName = "find_available_drivers"
+	handlerErr := errors.New("driver-service unavailable")
+
+	d := amqp.Delivery{
+		Exchange:   TripExchange,
+		RoutingKey: contracts.TripEventCreated,
+		MessageId:  "msg-1",
+		Body:       []byte(`{}`),
+	}
+
+	for attempt := 2; attempt <= len(DefaultRetryDelays)+1; attempt++ {
+		d = redeliver(queueName, d, handlerErr)
+		restoreRouting(&d)
+
+		if d.RoutingKey != contracts.TripEventCreated {
+			t.Errorf("attempt %d: routing key = %q, want %q", attempt, d.RoutingKey, contracts.TripEventCreated)
+		}
+		if d.Exchange != TripExchange {
+			t.Errorf("attempt %d: exchange = %q, want %q", attempt, d.Exchange, TripExchange)
+		}
+		if got := deliveryAttempt(d); got != attempt {
+			t.Errorf("attempt %d: delivery attempt = %d", attempt, got)
+		}
+	}
+}
+
+func TestRestoreRoutingFirstDelivery(t *testing.T) {
+	d := amqp.Delivery{Exchange: TripExchange, RoutingKey: contracts.TripEventCreated}
+	restoreRouting(&d)
+
+	if d.RoutingKey != contracts.TripEventCreated || d.Exchange != TripExchange {
+		t.Errorf("first delivery rerouted to %q/%q", d.Exchange, d.RoutingKey)
+	}
+}
+
+func TestRetryQueueName(t *testing.T) {
+	if got := retryQueueName("payment_status_update", 10*time.Second); got != "payment_status_update.retry.10s" {
+		t.Errorf("retryQueueName() = %q", got)
+	}
+}
+
+func TestRetryConfigTiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RetryConfig
+		want    []time.Duration
+		wantErr bool
+	}{
+		{"one tier per delay", RetryConfig{Delays: DefaultRetryDelays, MaxAttempts: 4}, DefaultRetryDelays, false},
+		{"fewer attempts than delays", RetryConfig{Delays: DefaultRetryDelays, MaxAttempts: 2}, []time.Duration{time.Second}, false},
+		{"last delay repeats", RetryConfig{Delays: []time.Duration{time.Second, 5 * time.Second}, MaxAttempts: 5}, []time.Duration{time.Second, 5 * time.Second, 5 * time.Second, 5 * time.Second}, false},
+		{"no retries", RetryConfig{MaxAttempts: 1}, []time.Duration{}, false},
+		{"no attempts", RetryConfig{Delays: DefaultRetryDelays}, nil, true},
+		{"retries without delays", RetryConfig{MaxAttempts: 3}, nil, true},
+		{"negative delay", RetryConfig{Delays: []time.Duration{-time.Second}, MaxAttempts: 2}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.tiers()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tiers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("tiers() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("tiers() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRetryDefaultConfig(t *testing.T) {
+	t.Setenv("RABBITMQ_RETRY_DELAYS", "2s, 30s")
+
+	config := NewRetryDefaultConfig()
+	if len(config.Delays) != 2 || config.Delays[0] != 2*time.Second || config.Delays[1] != 30*time.Second {
+		t.Errorf("Delays = %v, want [2s 30s]", config.Delays)
+	}
+	if config.MaxAttempts != 3 {
+		t.Errorf("MaxAttempts = %d, want 3", config.MaxAttempts)
+	}
+
+	t.Setenv("RABBITMQ_MAX_ATTEMPTS", "6")
+	if config := NewRetryDefaultConfig(); config.MaxAttempts != 6 {
+		t.Errorf("MaxAttempts = %d, want 6", config.MaxAttempts)
+	}
+}
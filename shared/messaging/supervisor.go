@@ -0,0 +1,244 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	reconnectInitialWait = 1 * time.Second
+	reconnectMaxWait     = 30 * time.Second
+)
+
+var ErrNotConnected = errors.New("not connected to RabbitMQ")
+
+// closeNotifications receives once the connection or one of its channels is closed. Each
+// gets its own Go channel: amqp091 closes every channel registered with NotifyClose, and
+// the connection shuts its AMQP channels down too, so a shared one would be closed twice.
+type closeNotifications struct {
+	conn    chan *amqp.Error
+	channel chan *amqp.Error
+	publish chan *amqp.Error
+}
+
+// wait blocks until one of the notifications fires or done is closed, reporting which.
+func (n *closeNotifications) wait(done <-chan struct{}) (*amqp.Error, bool) {
+	select {
+	case <-done:
+		return nil, false
+	case err := <-n.conn:
+		return err, true
+	case err := <-n.channel:
+		return err, true
+	case err := <-n.publish:
+		return err, true
+	}
+}
+
+// consumer is a registered consumer, started again on every new channel.
+type consumer struct {
+	start func(ch *amqp.Channel) error
+}
+
+// connect dials the broker, opens the channels and declares the topology. It returns the
+// notifications of the connection and its channels closing.
+func (r *RabbitMQ) connect() (*closeNotifications, error) {
+	conn, err := amqp.Dial(r.uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open a channel: %w", err)
+	}
+
+	// publishing gets its own channel in confirm mode, so that waiting for confirms
+	// never competes with deliveries to consumers
+	publishChannel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open the publishing channel: %w", err)
+	}
+
+	if err := publishChannel.Confirm(false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.conn = conn
+	r.channel = channel
+	r.publishChannel = publishChannel
+	r.returns = publishChannel.NotifyReturn(make(chan amqp.Return, 16))
+
+	if err := r.setupExchangesAndQueues(); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to set up exchanges and queues: %w", err)
+	}
+
+	// the first notification triggers a reconnect
+	closed := &closeNotifications{
+		conn:    conn.NotifyClose(make(chan *amqp.Error, 1)),
+		channel: channel.NotifyClose(make(chan *amqp.Error, 1)),
+		publish: publishChannel.NotifyClose(make(chan *amqp.Error, 1)),
+	}
+
+	for id, c := range r.consumers {
+		if err := c.start(channel); err != nil {
+			log.Printf("ERROR: Failed to restart consumer %d: %v", id, err)
+		}
+	}
+
+	r.setReady(true)
+
+	return closed, nil
+}
+
+// supervise waits for the connection to close and reconnects until Close is called.
+func (r *RabbitMQ) supervise(closed *closeNotifications) {
+	for {
+		err, ok := closed.wait(r.done)
+		if !ok {
+			return
+		}
+
+		r.mu.Lock()
+		r.setReady(false)
+		// a channel may close on its own, drop the connection with it
+		if r.conn != nil && !r.conn.IsClosed() {
+			r.conn.Close()
+		}
+		r.mu.Unlock()
+
+		log.Printf("RabbitMQ connection lost: %v", err)
+
+		closed = r.reconnect()
+		if closed == nil {
+			return
+		}
+
+		log.Println("RabbitMQ connection restored")
+	}
+}
+
+// reconnect retries connect with exponential backoff. It returns nil once Close is called.
+func (r *RabbitMQ) reconnect() *closeNotifications {
+	wait := reconnectInitialWait
+
+	for {
+		select {
+		case <-r.done:
+			return nil
+		case <-time.After(wait):
+		}
+
+		closed, err := r.connect()
+		if err == nil {
+			return closed
+		}
+
+		log.Printf("Failed to reconnect to RabbitMQ, retrying in %v: %v", wait, err)
+
+		wait *= 2
+		if wait > reconnectMaxWait {
+			wait = reconnectMaxWait
+		}
+	}
+}
+
+// registerConsumer remembers the consumer so that it is started again after a reconnect,
+// and starts it right away if the connection is up.
+func (r *RabbitMQ) registerConsumer(start func(ch *amqp.Channel) error) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+
+	if r.ready {
+		if err := start(r.channel); err != nil {
+			return 0, err
+		}
+	}
+
+	r.consumers[id] = &consumer{start: start}
+
+	return id, nil
+}
+
+// unregisterConsumer forgets the consumer so that it is not started again after a reconnect.
+// Cancelling the deliveries it is currently receiving is up to the caller.
+func (r *RabbitMQ) unregisterConsumer(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.consumers, id)
+}
+
+func (r *RabbitMQ) currentChannel() (*amqp.Channel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.ready {
+		return nil, ErrNotConnected
+	}
+
+	return r.channel, nil
+}
+
+func (r *RabbitMQ) currentPublishChannel() (*amqp.Channel, <-chan amqp.Return, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.ready {
+		return nil, nil, ErrNotConnected
+	}
+
+	return r.publishChannel, r.returns, nil
+}
+
+// setReady must be called with r.mu held.
+func (r *RabbitMQ) setReady(ready bool) {
+	if r.ready == ready {
+		return
+	}
+
+	r.ready = ready
+	if ready {
+		close(r.readyCh)
+	} else {
+		r.readyCh = make(chan struct{})
+	}
+}
+
+// Ready reports whether the connection is up and consumers are running.
+func (r *RabbitMQ) Ready() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.ready
+}
+
+// WaitReady blocks until the connection is ready or the context is done.
+func (r *RabbitMQ) WaitReady(ctx context.Context) error {
+	r.mu.RLock()
+	readyCh := r.readyCh
+	r.mu.RUnlock()
+
+	select {
+	case <-readyCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
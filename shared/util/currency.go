@@ -0,0 +1,30 @@
+package util
+
+import (
+	"math"
+	"strings"
+)
+
+// currencies whose minor unit is not a hundredth of the major unit, as supported by Stripe
+var currencyDecimals = map[string]int{
+	// zero-decimal currencies: amounts are whole units, 500 jpy is ¥500
+	"bif": 0, "clp": 0, "djf": 0, "gnf": 0, "jpy": 0, "kmf": 0, "krw": 0, "mga": 0,
+	"pyg": 0, "rwf": 0, "ugx": 0, "vnd": 0, "vuv": 0, "xaf": 0, "xof": 0, "xpf": 0,
+	// three-decimal currencies
+	"bhd": 3, "jod": 3, "kwd": 3, "omr": 3, "tnd": 3,
+}
+
+// CurrencyDecimals returns the number of decimals of the ISO 4217 currency, i.e. how many
+// minor units make a major unit: 2 for usd (cents), 0 for jpy.
+func CurrencyDecimals(currency string) int {
+	if decimals, ok := currencyDecimals[strings.ToLower(currency)]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// ToMajorUnits converts an amount in minor units of the currency to major units,
+// e.g. 1250 usd cents to 12.50 and 1250 jpy to 1250.
+func ToMajorUnits(amount int64, currency string) float64 {
+	return float64(amount) / math.Pow10(CurrencyDecimals(currency))
+}
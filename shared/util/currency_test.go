@@ -0,0 +1,33 @@
+package util
+
+import "testing"
+
+func TestToMajorUnits(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     float64
+		decimals int
+	}{
+		{1250, "usd", 12.5, 2},
+		{1250, "USD", 12.5, 2},
+		{1, "eur", 0.01, 2},
+		{0, "gbp", 0, 2},
+		{1250, "jpy", 1250, 0},
+		{500, "krw", 500, 0},
+		{1250, "kwd", 1.25, 3},
+		{1, "bhd", 0.001, 3},
+		{1250, "", 12.5, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			if got := CurrencyDecimals(tt.currency); got != tt.decimals {
+				t.Errorf("CurrencyDecimals(%q) = %d, want %d", tt.currency, got, tt.decimals)
+			}
+			if got := ToMajorUnits(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("ToMajorUnits(%d, %q) = %v, want %v", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 "use client"
 
 import { useDriverStreamConnection } from "../hooks/useDriverStreamConnection"
+import { useSession } from "../hooks/useSession"
 import { MapContainer, Marker, Popup, TileLayer } from 'react-leaflet'
 import L from 'leaflet';
 import { MapClickHandler } from './MapClickHandler';
@@ -38,7 +39,8 @@ const destinationMarker = new L.Icon({
 
 export const DriverMap = ({ packageSlug }: { packageSlug: CarPackageSlug }) => {
   const mapRef = useRef<L.Map>(null)
-  const userID = useMemo(() => crypto.randomUUID(), [])
+  const { session } = useSession("driver")
+  const userID = session?.userID ?? ""
   const [riderLocation, setRiderLocation] = useState<Coordinate>(START_LOCATION)
 
   const driverGeohash = useMemo(() =>
@@ -56,7 +58,7 @@ export const DriverMap = ({ packageSlug }: { packageSlug: CarPackageSlug }) => {
   } = useDriverStreamConnection({
     location: riderLocation,
     geohash: driverGeohash,
-    userID,
+    token: session?.token ?? "",
     packageSlug,
   })
 
@@ -78,7 +80,6 @@ export const DriverMap = ({ packageSlug }: { packageSlug: CarPackageSlug }) => {
       data: {
         tripID: requestedTrip.id,
         riderID: requestedTrip.userID,
-        driver: driver,
       }
     })
 
@@ -97,7 +98,6 @@ export const DriverMap = ({ packageSlug }: { packageSlug: CarPackageSlug }) => {
       data: {
         tripID: requestedTrip.id,
         riderID: requestedTrip.userID,
-        driver: driver,
       }
     })
 
@@ -2,6 +2,7 @@ import { Button } from "./ui/button"
 import { Clock } from 'lucide-react'
 import { RouteFare, TripPreview } from '../types'
 import { convertMetersToKilometers, convertSecondsToMinutes } from "../utils/math"
+import { formatMinorUnits } from "../utils/currency"
 import { cn } from "../lib/utils"
 import { PackagesMeta } from "./PackagesMeta"
 
@@ -25,7 +26,7 @@ export function DriverList({ trip, onPackageSelect, onCancel }: DriverListProps)
         <div className="space-y-4">
           {trip?.rideFares.map((fare) => {
             const Icon = PackagesMeta[fare.packageSlug].icon;
-            const price = fare.totalPriceInCents && `$${(fare.totalPriceInCents / 100).toFixed(2)}`
+            const price = fare.totalPriceInCents && formatMinorUnits(fare.totalPriceInCents, fare.currency)
 
             return (
               <div
@@ -47,6 +48,9 @@ export function DriverList({ trip, onPackageSelect, onCancel }: DriverListProps)
                 </div>
                 <div className="text-right">
                   <p className="font-semibold">{price}</p>
+                  {fare.surgeMultiplier && fare.surgeMultiplier > 1 && (
+                    <p className="text-xs text-orange-600">High demand ×{fare.surgeMultiplier.toFixed(2)}</p>
+                  )}
                 </div>
               </div>
             );
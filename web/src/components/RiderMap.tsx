@@ -2,10 +2,11 @@
 
 import Image from 'next/image';
 import { useRiderStreamConnection } from '../hooks/useRiderStreamConnection';
+import { useSession } from '../hooks/useSession';
 import { MapContainer, Marker, Popup, Rectangle, TileLayer } from 'react-leaflet'
 import L from 'leaflet';
 import { getGeohashBounds } from '../utils/geohash';
-import { useMemo, useRef, useState } from 'react';
+import { useRef, useState } from 'react';
 import { MapClickHandler } from './MapClickHandler';
 import { Button } from './ui/button';
 import { RouteFare, RequestRideProps, TripPreview, HTTPTripStartResponse } from "../types";
@@ -35,7 +36,8 @@ export default function RiderMap({ onRouteSelected }: RiderMapProps) {
     const [selectedCarPackage] = useState<RouteFare | null>(null)
     const [destination, setDestination] = useState<[number, number] | null>(null)
     const mapRef = useRef<L.Map>(null)
-    const userID = useMemo(() => crypto.randomUUID(), [])
+    const { session } = useSession("rider")
+    const token = session?.token ?? ""
     const debounceTimeoutRef = useRef<NodeJS.Timeout | null>(null);
 
     const location = {
@@ -49,8 +51,9 @@ export default function RiderMap({ onRouteSelected }: RiderMapProps) {
         tripStatus,
         assignedDriver,
         paymentSession,
+        paymentError,
         resetTripStatus
-    } = useRiderStreamConnection(location, userID);
+    } = useRiderStreamConnection(token);
 
     console.log(tripStatus)
 
@@ -91,7 +94,6 @@ export default function RiderMap({ onRouteSelected }: RiderMapProps) {
     const requestRidePreview = async (props: RequestRideProps): Promise<HTTPTripPreviewResponse> => {
         const { pickup, destination } = props
         const payload = {
-            userID: userID,
             pickup: {
                 latitude: pickup[0],
                 longitude: pickup[1],
@@ -104,6 +106,7 @@ export default function RiderMap({ onRouteSelected }: RiderMapProps) {
 
         const response = await fetch(`${API_URL}${BackendEndpoints.PREVIEW_TRIP}`, {
             method: 'POST',
+            headers: { Authorization: `Bearer ${token}` },
             body: JSON.stringify(payload),
         })
         const { data } = await response.json() as { data: HTTPTripPreviewResponse }
@@ -113,7 +116,6 @@ export default function RiderMap({ onRouteSelected }: RiderMapProps) {
     const handleStartTrip = async (fare: RouteFare) => {
         const payload = {
             rideFareID: fare.id,
-            userID: userID,
         } as HTTPTripStartRequestPayload
 
         if (!fare.id) {
@@ -123,6 +125,7 @@ export default function RiderMap({ onRouteSelected }: RiderMapProps) {
 
         const response = await fetch(`${API_URL}${BackendEndpoints.START_TRIP}`, {
             method: 'POST',
+            headers: { Authorization: `Bearer ${token}` },
             body: JSON.stringify(payload),
         })
         const data = await response.json() as HTTPTripStartResponse
@@ -229,6 +232,7 @@ export default function RiderMap({ onRouteSelected }: RiderMapProps) {
                     assignedDriver={assignedDriver}
                     status={tripStatus}
                     paymentSession={paymentSession}
+                    paymentError={paymentError}
                     onPackageSelect={handleStartTrip}
                     onCancel={handleCancelTrip}
                 />
@@ -14,6 +14,7 @@ interface TripOverviewProps {
   status: TripEvents | null;
   assignedDriver?: Driver | null;
   paymentSession?: PaymentEventSessionCreatedData | null;
+  paymentError?: string | null;
   onPackageSelect: (carPackage: RouteFare) => void;
   onCancel: () => void;
 }
@@ -23,6 +24,7 @@ export const RiderTripOverview = ({
   status,
   assignedDriver,
   paymentSession,
+  paymentError,
   onPackageSelect,
   onCancel,
 }: TripOverviewProps) => {
@@ -39,7 +41,9 @@ export const RiderTripOverview = ({
     return (
       <TripOverviewCard
         title="Payment Required"
-        description="Please complete the payment to confirm your trip"
+        description={paymentError
+          ? `Your last payment failed: ${paymentError}. Please try again.`
+          : "Please complete the payment to confirm your trip"}
       >
         <div className="flex flex-col gap-4">
           <DriverCard driver={assignedDriver} />
@@ -54,6 +58,32 @@ export const RiderTripOverview = ({
     )
   }
 
+  if (status === TripEvents.PaymentFailed || status === TripEvents.PaymentCancelled) {
+    return (
+      <TripOverviewCard
+        title="Payment failed"
+        description={`Your payment did not go through: ${paymentError}. If you can retry, a new payment will show up in a moment.`}
+      >
+        <Button variant="destructive" className="w-full" onClick={onCancel}>
+          Cancel current trip
+        </Button>
+      </TripOverviewCard>
+    )
+  }
+
+  if (status === TripEvents.PaymentRefunded) {
+    return (
+      <TripOverviewCard
+        title="Payment refunded"
+        description="The payment of your trip has been refunded."
+      >
+        <Button variant="outline" className="w-full" onClick={onCancel}>
+          Go back
+        </Button>
+      </TripOverviewCard>
+    )
+  }
+
   if (status === TripEvents.NoDriversFound) {
     return (
       <TripOverviewCard
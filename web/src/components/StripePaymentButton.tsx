@@ -15,6 +15,12 @@ export const StripePaymentButton = ({
   isLoading = false,
 }: StripePaymentButtonProps) => {
   const handlePayment = async () => {
+    // the session is paid on the page of the payment processor, e.g. the local fake checkout
+    if (paymentSession.checkoutURL) {
+      window.location.href = paymentSession.checkoutURL
+      return
+    }
+
     const stripe = await stripePromise
 
     if (!stripe) {
@@ -30,7 +36,7 @@ export const StripePaymentButton = ({
     }
   }
 
-  if (!process.env.NEXT_PUBLIC_STRIPE_PUBLISHABLE_KEY) {
+  if (!paymentSession.checkoutURL && !process.env.NEXT_PUBLIC_STRIPE_PUBLISHABLE_KEY) {
     return (
       <Button
         disabled
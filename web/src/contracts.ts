@@ -3,8 +3,12 @@ import { Coordinate, Driver, Route, RouteFare, Trip } from "./types";
 
 // These are the endpoints the API Gateway must have for the frontend to work correctly
 export enum BackendEndpoints {
+  LOGIN = "/auth/login",
   PREVIEW_TRIP = "/trip/preview",
   START_TRIP = "/trip/start",
+  CANCEL_TRIP = "/trip/cancel",
+  GET_TRIP = "/trip/{id}",
+  LIST_USER_TRIPS = "/users/{id}/trips",
   WS_DRIVERS = "/drivers",
   WS_RIDERS = "/riders",
 }
@@ -20,21 +24,71 @@ export enum TripEvents {
   DriverTripAccept = "driver.cmd.trip_accept",
   DriverTripDecline = "driver.cmd.trip_decline",
   DriverRegister = "driver.cmd.register",
+  DriverTripCancelled = "driver.event.trip_cancelled",
+  DriverLocationUpdated = "driver.event.location_updated",
+  DriverTripUnavailable = "driver.event.trip_unavailable",
   PaymentSessionCreated = "payment.event.session_created",
+  PaymentFailed = "payment.event.failed",
+  PaymentCancelled = "payment.event.cancelled",
+  PaymentRefunded = "payment.event.refunded",
+}
+
+// Version of the websocket protocol the frontend speaks, sent as the v query parameter
+export const WS_PROTOCOL_VERSION = 1;
+
+// Messages of the websocket protocol itself, see shared/contracts/ws.go
+export enum WsProtocolMessages {
+  Hello = "ws.hello",
+  Ack = "ws.ack",
+  Error = "ws.error",
+}
+
+export interface WsHello {
+  type: WsProtocolMessages.Hello;
+  data: {
+    version: number;
+    pingIntervalMs: number;
+    pongTimeoutMs: number;
+    serverTime: number;
+  };
+}
+
+export interface WsAck {
+  type: WsProtocolMessages.Ack;
+  data: { id: string };
+}
+
+export interface WsError {
+  type: WsProtocolMessages.Error;
+  data: {
+    id?: string;
+    code: "bad_request" | "unknown_type" | "trip_unavailable" | "internal";
+    message: string;
+  };
+}
+
+export type WsProtocolMessage = WsHello | WsAck | WsError;
+
+export function isWsProtocolMessage(message: { type: string }): message is WsProtocolMessage {
+  return Object.values(WsProtocolMessages).includes(message.type as WsProtocolMessages);
 }
 
 // Messages sent from the server to the client via the websocket
 export type ServerWsMessage =
   | PaymentSessionCreatedRequest
+  | PaymentStatusUpdateRequest
   | DriverAssignedRequest
   | DriverLocationRequest
+  | DriverLocationUpdatedRequest
   | DriverTripRequest
   | DriverRegisterRequest
+  | DriverTripUnavailableRequest
   | TripCreatedRequest
   | NoDriversFoundRequest;
 
-// Messages sent from the client to the server via the websocket
-export type ClientWsMessage = DriverResponseToTripResponse
+// Messages sent from the client to the server via the websocket. The server acks or
+// rejects every message that has an id.
+export type ClientWsMessage = DriverResponseToTripResponse & { id?: string }
 
 interface TripCreatedRequest {
   type: TripEvents.Created;
@@ -57,6 +111,7 @@ interface DriverTripRequest {
 export interface PaymentEventSessionCreatedData {
   tripID: string;
   sessionID: string;
+  checkoutURL?: string;
   amount: number;
   currency: string;
 }
@@ -66,9 +121,27 @@ interface PaymentSessionCreatedRequest {
   data: PaymentEventSessionCreatedData;
 }
 
+// A failed or expired checkout is followed by a new payment.event.session_created, unless
+// the rider is out of attempts
+export interface PaymentStatusUpdateData {
+  tripID: string;
+  userID: string;
+  driverID: string;
+  sessionID?: string;
+  paymentType?: "cancellation_fee";
+  reason?: string;
+}
+
+interface PaymentStatusUpdateRequest {
+  type: TripEvents.PaymentFailed | TripEvents.PaymentCancelled | TripEvents.PaymentRefunded;
+  data: PaymentStatusUpdateData;
+}
+
 interface DriverAssignedRequest {
   type: TripEvents.DriverAssigned;
-  data: Trip;
+  data: {
+    trip: Trip;
+  };
 }
 
 interface DriverLocationRequest {
@@ -76,12 +149,28 @@ interface DriverLocationRequest {
   data: Driver[];
 }
 
+export interface DriverLocationUpdatedData {
+  driverID: string;
+  tripID: string;
+  location: Coordinate;
+  geohash: string;
+}
+
+interface DriverTripUnavailableRequest {
+  type: TripEvents.DriverTripUnavailable;
+  data: { tripID: string };
+}
+
+interface DriverLocationUpdatedRequest {
+  type: TripEvents.DriverLocationUpdated;
+  data: DriverLocationUpdatedData;
+}
+
 interface DriverResponseToTripResponse {
   type: TripEvents.DriverTripAccept | TripEvents.DriverTripDecline;
   data: {
     tripID: string;
     riderID: string;
-    driver: Driver;
   };
 }
 
@@ -92,11 +181,42 @@ export interface HTTPTripPreviewResponse {
 
 export interface HTTPTripStartRequestPayload {
   rideFareID: string;
+}
+
+export interface HTTPTripCancelRequestPayload {
+  tripID: string;
+}
+
+export interface HTTPLoginRequestPayload {
+  username?: string;
+  password?: string;
+  role: "rider" | "driver";
+}
+
+export interface HTTPLoginResponse {
+  token: string;
+  expiresAt: string;
   userID: string;
+  role: "rider" | "driver";
+}
+
+export interface HTTPTripCancelResponse {
+  trip: Trip;
+  cancelledBy: "rider" | "driver";
+  cancellationFeeInCents: number;
+}
+
+export interface HTTPGetTripResponse {
+  trip: Trip;
+}
+
+// Query parameters: pageSize (default 20, max 100) and pageToken
+export interface HTTPListTripsResponse {
+  trips?: Trip[];
+  nextPageToken?: string;
 }
 
 export interface HTTPTripPreviewRequestPayload {
-  userID: string;
   pickup: Coordinate;
   destination: Coordinate;
 }
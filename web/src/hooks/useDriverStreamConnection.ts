@@ -1,7 +1,7 @@
 import { useEffect, useState } from 'react';
 import { WEBSOCKET_URL } from "../constants";
 import { Trip, Driver, CarPackageSlug } from '../types';
-import { ServerWsMessage, TripEvents, isValidWsMessage, isValidTripEvent, ClientWsMessage, BackendEndpoints } from '../contracts';
+import { ServerWsMessage, TripEvents, isValidWsMessage, isValidTripEvent, ClientWsMessage, BackendEndpoints, WS_PROTOCOL_VERSION, WsProtocolMessage, isWsProtocolMessage, WsProtocolMessages } from '../contracts';
 
 interface useDriverConnectionProps {
   location: {
@@ -9,14 +9,14 @@ interface useDriverConnectionProps {
     longitude: number;
   };
   geohash: string;
-  userID: string;
+  token: string;
   packageSlug: CarPackageSlug;
 }
 
 export const useDriverStreamConnection = ({
   location,
   geohash,
-  userID,
+  token,
   packageSlug
 }: useDriverConnectionProps) => {
   const [requestedTrip, setRequestedTrip] = useState<Trip | null>(null)
@@ -26,9 +26,9 @@ export const useDriverStreamConnection = ({
   const [driver, setDriver] = useState<Driver | null>(null);
 
   useEffect(() => {
-    if (!userID) return;
+    if (!token) return;
 
-    const websocket = new WebSocket(`${WEBSOCKET_URL}${BackendEndpoints.WS_DRIVERS}?userID=${userID}&packageSlug=${packageSlug}`);
+    const websocket = new WebSocket(`${WEBSOCKET_URL}${BackendEndpoints.WS_DRIVERS}?token=${encodeURIComponent(token)}&packageSlug=${packageSlug}&v=${WS_PROTOCOL_VERSION}`);
     setWs(websocket);
 
     websocket.onopen = () => {
@@ -45,7 +45,15 @@ export const useDriverStreamConnection = ({
     };
 
     websocket.onmessage = (event) => {
-      const message = JSON.parse(event.data) as ServerWsMessage;
+      const message = JSON.parse(event.data) as ServerWsMessage | WsProtocolMessage;
+
+      // the server pings the socket itself, the browser answers without us
+      if (message && isWsProtocolMessage(message)) {
+        if (message.type === WsProtocolMessages.Error) {
+          setError(message.data.message);
+        }
+        return;
+      }
 
       if (!message || !isValidWsMessage(message)) {
         setError(`Unknown message type "${message}", allowed types are: ${Object.values(TripEvents).join(', ')}`);
@@ -60,6 +68,10 @@ export const useDriverStreamConnection = ({
         case TripEvents.DriverRegister:
           setDriver(message.data);
           break;
+        case TripEvents.DriverTripUnavailable:
+          // the offer expired or went to someone else before the accept arrived
+          setRequestedTrip(null);
+          break;
       }
 
 
@@ -86,11 +98,11 @@ export const useDriverStreamConnection = ({
       }
     };
     // eslint-disable-next-line react-hooks/exhaustive-deps
-  }, [userID]);
+  }, [token]);
 
   const sendMessage = (message: ClientWsMessage) => {
     if (ws?.readyState === WebSocket.OPEN) {
-      ws.send(JSON.stringify(message));
+      ws.send(JSON.stringify({ id: crypto.randomUUID(), ...message }));
     } else {
       setError('WebSocket is not connected');
     }
@@ -1,35 +1,32 @@
 import { useEffect, useState } from 'react';
 import { WEBSOCKET_URL } from "../constants";
 import { Trip } from '../types';
-import { Driver, Coordinate } from '../types';
-import { PaymentEventSessionCreatedData, TripEvents, ServerWsMessage, isValidWsMessage, BackendEndpoints } from '../contracts';
+import { Driver } from '../types';
+import { PaymentEventSessionCreatedData, TripEvents, ServerWsMessage, isValidWsMessage, BackendEndpoints, WS_PROTOCOL_VERSION, WsProtocolMessage, isWsProtocolMessage, WsProtocolMessages } from '../contracts';
 
-export function useRiderStreamConnection(location: Coordinate, userID: string) {
+export function useRiderStreamConnection(token: string) {
   const [drivers, setDrivers] = useState<Driver[]>([]);
   const [tripStatus, setTripStatus] = useState<TripEvents | null>(null);
   const [paymentSession, setPaymentSession] = useState<PaymentEventSessionCreatedData | null>(null);
+  const [paymentError, setPaymentError] = useState<string | null>(null);
   const [assignedDriver, setAssignedDriver] = useState<Trip["driver"] | null>(null);
   const [error, setError] = useState<string | null>(null);
 
   useEffect(() => {
-    if (!userID) return;
+    if (!token) return;
 
-    const ws = new WebSocket(`${WEBSOCKET_URL}${BackendEndpoints.WS_RIDERS}?userID=${userID}`);
-
-    ws.onopen = () => {
-      // Send initial location
-      if (location) {
-        ws.send(JSON.stringify({
-          type: TripEvents.DriverLocation,
-          data: {
-            location,
-          }
-        }));
-      }
-    };
+    const ws = new WebSocket(`${WEBSOCKET_URL}${BackendEndpoints.WS_RIDERS}?token=${encodeURIComponent(token)}&v=${WS_PROTOCOL_VERSION}`);
 
     ws.onmessage = (event) => {
-      const message = JSON.parse(event.data) as ServerWsMessage;
+      const message = JSON.parse(event.data) as ServerWsMessage | WsProtocolMessage;
+
+      // the server pings the socket itself, the browser answers without us
+      if (message && isWsProtocolMessage(message)) {
+        if (message.type === WsProtocolMessages.Error) {
+          console.warn('WebSocket message rejected:', message.data);
+        }
+        return;
+      }
 
       if (!message || !isValidWsMessage(message)) {
         setError(`Unknown message type "${message}", allowed types are: ${Object.values(TripEvents).join(', ')}`);
@@ -44,8 +41,23 @@ export function useRiderStreamConnection(location: Coordinate, userID: string) {
           setPaymentSession(message.data);
           setTripStatus(message.type);
           break;
+        case TripEvents.PaymentFailed:
+        case TripEvents.PaymentCancelled:
+          // the session can no longer be paid, a new one follows if the rider may retry
+          setPaymentSession(null);
+          setPaymentError(message.data.reason ?? 'the payment did not go through');
+          setTripStatus(message.type);
+          break;
+        case TripEvents.PaymentRefunded:
+          setTripStatus(message.type);
+          break;
+        case TripEvents.DriverLocationUpdated: {
+          const { location, geohash } = message.data;
+          setAssignedDriver((driver) => driver ? { ...driver, location, geohash } : driver);
+          break;
+        }
         case TripEvents.DriverAssigned:
-          setAssignedDriver(message.data.driver);
+          setAssignedDriver(message.data.trip.driver);
           setTripStatus(message.type);
           break;
         case TripEvents.Created:
@@ -73,12 +85,13 @@ export function useRiderStreamConnection(location: Coordinate, userID: string) {
       }
     };
     // eslint-disable-next-line react-hooks/exhaustive-deps
-  }, [userID]);
+  }, [token]);
 
   const resetTripStatus = () => {
     setTripStatus(null);
     setPaymentSession(null);
+    setPaymentError(null);
   }
 
-  return { drivers, assignedDriver, error, tripStatus, paymentSession, resetTripStatus };
+  return { drivers, assignedDriver, error, tripStatus, paymentSession, paymentError, resetTripStatus };
 }
@@ -0,0 +1,44 @@
+import { useEffect, useState } from 'react';
+import { API_URL } from "../constants";
+import { BackendEndpoints, HTTPLoginRequestPayload, HTTPLoginResponse } from '../contracts';
+
+// useSession logs in as a guest with the given role and returns the access token to send
+// to the API gateway, as a bearer token on requests and a query parameter on WebSockets.
+export function useSession(role: HTTPLoginRequestPayload["role"]) {
+  const [session, setSession] = useState<HTTPLoginResponse | null>(null);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    let cancelled = false;
+
+    const login = async () => {
+      const response = await fetch(`${API_URL}${BackendEndpoints.LOGIN}`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ role } as HTTPLoginRequestPayload),
+      });
+
+      if (!response.ok) {
+        throw new Error(await response.text());
+      }
+
+      const { data } = await response.json() as { data: HTTPLoginResponse };
+      if (!cancelled) {
+        setSession(data);
+      }
+    };
+
+    login().catch((err) => {
+      console.error('Login failed:', err);
+      if (!cancelled) {
+        setError('Login failed');
+      }
+    });
+
+    return () => {
+      cancelled = true;
+    };
+  }, [role]);
+
+  return { session, error };
+}
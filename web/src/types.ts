@@ -5,6 +5,7 @@ export interface Trip {
     selectedFare: RouteFare;
     route: Route;
     driver?: Driver;
+    createdAt?: number;
     trip: Trip;
 }
 
@@ -33,11 +34,24 @@ export enum CarPackageSlug {
     LUXURY = "luxury",
 }
 
+// Amounts are in minor units of the fare currency
+export interface FareBreakdown {
+    baseFare?: number,
+    distanceFare?: number,
+    timeFare?: number,
+    bookingFee?: number,
+    minimumFareAdjustment?: number,
+    surgeFare?: number,
+}
+
 export interface RouteFare {
     id: string,
     packageSlug: CarPackageSlug,
     basePrice: number,
     totalPriceInCents?: number,
+    currency?: string,
+    breakdown?: FareBreakdown,
+    surgeMultiplier?: number,
     expiresAt: Date,
     route: Route,
 }
@@ -0,0 +1,11 @@
+// Formats an amount in minor units of the currency, e.g. 1250 usd as $12.50 and 1250 jpy
+// (a zero-decimal currency) as ¥1,250
+export function formatMinorUnits(amount: number, currency = "usd") {
+  const format = new Intl.NumberFormat(undefined, {
+    style: "currency",
+    currency: currency.toUpperCase(),
+  })
+
+  const decimals = format.resolvedOptions().maximumFractionDigits ?? 2
+  return format.format(amount / 10 ** decimals)
+}